@@ -1,9 +1,13 @@
 package construct
 
 import (
+	"cmp"
+	"fmt"
 	"go/ast"
 	"go/token"
+	"net/http"
 	"slices"
+	"strconv"
 
 	"go.ufukty.com/gohandlers/pkg/inspects"
 )
@@ -15,6 +19,45 @@ func ternary[T any](cond bool, t, f T) T {
 	return f
 }
 
+func quotes(s string) string {
+	return fmt.Sprintf("%q", s)
+}
+
+// status refers to the success status code, http.StatusOK when unspecified
+func status(code int) ast.Expr {
+	if code == 0 || code == http.StatusOK {
+		return &ast.SelectorExpr{X: &ast.Ident{Name: "http"}, Sel: &ast.Ident{Name: "StatusOK"}}
+	}
+	return &ast.BasicLit{Kind: token.INT, Value: strconv.Itoa(code)}
+}
+
+// dynamic reports if the response binding type decides the status code
+func dynamic(hi inspects.Info) bool {
+	return hi.ResponseType != nil && hi.ResponseType.Status != ""
+}
+
+// unexpected checks the status code against the success status code. Any
+// 2xx code is accepted when the response binding type decides it.
+func unexpected(hi inspects.Info) ast.Expr {
+	code := &ast.SelectorExpr{X: &ast.Ident{Name: "rs"}, Sel: &ast.Ident{Name: "StatusCode"}}
+	if dynamic(hi) {
+		return &ast.BinaryExpr{
+			X:  &ast.BinaryExpr{X: code, Op: token.LSS, Y: &ast.BasicLit{Kind: token.INT, Value: "200"}},
+			Op: token.LOR,
+			Y:  &ast.BinaryExpr{X: code, Op: token.GTR, Y: &ast.BasicLit{Kind: token.INT, Value: "299"}},
+		}
+	}
+	return &ast.BinaryExpr{X: code, Op: token.NEQ, Y: status(hi.Status)}
+}
+
+// expected is the description of the accepted status codes
+func expected(hi inspects.Info) string {
+	if dynamic(hi) {
+		return "2xx"
+	}
+	return strconv.Itoa(cmp.Or(hi.Status, http.StatusOK))
+}
+
 func pool() ast.Decl {
 	return &ast.GenDecl{
 		Tok: token.TYPE,
@@ -84,11 +127,7 @@ func clientMethod(hn string, hi inspects.Info, pkgsrc string, imported bool) *as
 			Params: &ast.FieldList{List: []*ast.Field{
 				{
 					Names: []*ast.Ident{{Name: "bq"}},
-					Type: &ast.StarExpr{X: ternary[ast.Expr](
-						imported,
-						&ast.SelectorExpr{X: &ast.Ident{Name: pkgsrc}, Sel: &ast.Ident{Name: hi.RequestType.Typename}},
-						&ast.Ident{Name: hi.RequestType.Typename},
-					)},
+					Type:  &ast.StarExpr{X: typeref(hi.RequestType, pkgsrc, imported)},
 				},
 			}},
 		},
@@ -96,11 +135,7 @@ func clientMethod(hn string, hi inspects.Info, pkgsrc string, imported bool) *as
 	}
 
 	if hi.ResponseType != nil {
-		rt := ternary[ast.Expr](
-			imported,
-			&ast.SelectorExpr{X: &ast.Ident{Name: pkgsrc}, Sel: &ast.Ident{Name: hi.ResponseType.Typename}},
-			&ast.Ident{Name: hi.ResponseType.Typename},
-		)
+		rt := typeref(hi.ResponseType, pkgsrc, imported)
 		fd.Type.Results = &ast.FieldList{List: []*ast.Field{
 			{Type: &ast.StarExpr{X: rt}},
 			{Type: &ast.Ident{Name: "error"}},
@@ -192,11 +227,7 @@ func clientMethod(hn string, hi inspects.Info, pkgsrc string, imported bool) *as
 			},
 		},
 		&ast.IfStmt{
-			Cond: &ast.BinaryExpr{
-				X:  &ast.SelectorExpr{X: &ast.Ident{Name: "rs"}, Sel: &ast.Ident{Name: "StatusCode"}},
-				Op: token.NEQ,
-				Y:  &ast.SelectorExpr{X: &ast.Ident{Name: "http"}, Sel: &ast.Ident{Name: "StatusOK"}},
-			},
+			Cond: unexpected(hi),
 			Body: &ast.BlockStmt{
 				List: []ast.Stmt{
 					&ast.ReturnStmt{
@@ -205,7 +236,7 @@ func clientMethod(hn string, hi inspects.Info, pkgsrc string, imported bool) *as
 							&ast.CallExpr{
 								Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "fmt"}, Sel: &ast.Ident{Name: "Errorf"}},
 								Args: []ast.Expr{
-									&ast.BasicLit{Kind: token.STRING, Value: `"non-200 status code: %d (%s)"`},
+									&ast.BasicLit{Kind: token.STRING, Value: quotes(fmt.Sprintf("non-%s status code: %%d (%%s)", expected(hi)))},
 									&ast.SelectorExpr{X: &ast.Ident{Name: "rs"}, Sel: &ast.Ident{Name: "StatusCode"}},
 									&ast.CallExpr{
 										Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "http"}, Sel: &ast.Ident{Name: "StatusText"}},
@@ -221,11 +252,7 @@ func clientMethod(hn string, hi inspects.Info, pkgsrc string, imported bool) *as
 	)
 
 	if hi.ResponseType != nil {
-		rt := ternary[ast.Expr](
-			imported,
-			&ast.SelectorExpr{X: &ast.Ident{Name: pkgsrc}, Sel: &ast.Ident{Name: hi.ResponseType.Typename}},
-			&ast.Ident{Name: hi.ResponseType.Typename},
-		)
+		rt := typeref(hi.ResponseType, pkgsrc, imported)
 
 		fd.Body.List = append(fd.Body.List,
 			&ast.AssignStmt{
@@ -9,7 +9,7 @@ import (
 func File(infoss map[inspects.Receiver]map[string]inspects.Info, pkgdst, pkgsrc, importpkg string) *ast.File {
 	f := &ast.File{
 		Name:  &ast.Ident{Name: pkgdst},
-		Decls: []ast.Decl{imports(importpkg)},
+		Decls: []ast.Decl{imports(infoss, importpkg)},
 	}
 	f.Decls = append(f.Decls,
 		iface(infoss, pkgsrc, importpkg != ""),
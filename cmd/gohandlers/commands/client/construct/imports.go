@@ -4,11 +4,15 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
+	"maps"
+	"path"
+	"slices"
 
 	"go.ufukty.com/gohandlers/cmd/gohandlers/internal/pretty/sort"
+	"go.ufukty.com/gohandlers/pkg/inspects"
 )
 
-func imports(importpkg string) ast.Decl {
+func imports(infoss map[inspects.Receiver]map[string]inspects.Info, importpkg string) ast.Decl {
 	imports := []ast.Spec{
 		&ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: `"fmt"`}},
 		&ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: `"net/http"`}},
@@ -18,6 +22,24 @@ func imports(importpkg string) ast.Decl {
 			&ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("%q", importpkg)}},
 		)
 	}
+	// binding types declared in other packages
+	paths := map[string]string{}
+	for _, infos := range infoss {
+		for _, info := range infos {
+			for _, bti := range []*inspects.BindingTypeInfo{info.RequestType, info.ResponseType} {
+				if bti != nil && !bti.Local() && bti.Package != importpkg {
+					paths[bti.Package] = bti.PackageName
+				}
+			}
+		}
+	}
+	for _, p := range slices.Sorted(maps.Keys(paths)) {
+		spec := &ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("%q", p)}}
+		if path.Base(p) != paths[p] {
+			spec.Name = &ast.Ident{Name: paths[p]}
+		}
+		imports = append(imports, spec)
+	}
 	sort.Imports(imports)
 	return &ast.GenDecl{
 		Tok:   token.IMPORT,
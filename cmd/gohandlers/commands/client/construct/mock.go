@@ -10,24 +10,31 @@ import (
 	"go.ufukty.com/gohandlers/pkg/inspects"
 )
 
+// typeref refers to the binding type in the client file
+func typeref(bti *inspects.BindingTypeInfo, pkgsrc string, imported bool) ast.Expr {
+	if !bti.Local() {
+		return &ast.SelectorExpr{X: &ast.Ident{Name: bti.PackageName}, Sel: &ast.Ident{Name: bti.Typename}}
+	}
+	if imported {
+		return &ast.SelectorExpr{X: &ast.Ident{Name: pkgsrc}, Sel: &ast.Ident{Name: bti.Typename}}
+	}
+	return &ast.Ident{Name: bti.Typename}
+}
+
 func methodtype(hi inspects.Info, pkgsrc string, imported, namedparams bool) *ast.FuncType {
 	var bq ast.Expr
 	var bn ast.Expr
 
 	if hi.RequestType == nil {
 		// TODO:
-	} else if imported {
-		bq = &ast.SelectorExpr{X: &ast.Ident{Name: pkgsrc}, Sel: &ast.Ident{Name: hi.RequestType.Typename}}
 	} else {
-		bq = &ast.Ident{Name: hi.RequestType.Typename}
+		bq = typeref(hi.RequestType, pkgsrc, imported)
 	}
 
 	if hi.ResponseType == nil {
 		bn = &ast.SelectorExpr{X: &ast.Ident{Name: "http"}, Sel: &ast.Ident{Name: "Response"}}
-	} else if imported {
-		bn = &ast.SelectorExpr{X: &ast.Ident{Name: pkgsrc}, Sel: &ast.Ident{Name: hi.ResponseType.Typename}}
 	} else {
-		bn = &ast.Ident{Name: hi.ResponseType.Typename}
+		bn = typeref(hi.ResponseType, pkgsrc, imported)
 	}
 
 	param1 := &ast.Field{Type: &ast.StarExpr{X: bq}}
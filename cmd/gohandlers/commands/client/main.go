@@ -5,9 +5,13 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 
 	"go.ufukty.com/gohandlers/cmd/gohandlers/commands/client/construct"
+	"go.ufukty.com/gohandlers/cmd/gohandlers/internal/config"
 	"go.ufukty.com/gohandlers/cmd/gohandlers/internal/pretty"
+	"go.ufukty.com/gohandlers/cmd/gohandlers/internal/report"
+	"go.ufukty.com/gohandlers/cmd/gohandlers/internal/targets"
 	"go.ufukty.com/gohandlers/pkg/inspects"
 )
 
@@ -16,15 +20,83 @@ type Args struct {
 	Out     string
 	Pkg     string
 	Import  string
+	Types   bool
 	Verbose bool
+
+	Config      string // the config file to read the conventions from
+	Conventions inspects.Conventions
+}
+
+// Generate writes the client file for the inspected package in dir. Packages
+// without handlers are skipped when they are matched by a pattern.
+func Generate(args Args, pkg *inspects.Package, dir, out, importpkg string, matched bool) error {
+	infoss, pkgsrc := pkg.Handlers, pkg.Name
+
+	if matched && len(infoss) == 0 {
+		if args.Verbose {
+			fmt.Printf("skipping %s as it has no handlers\n", dir)
+		}
+		return nil
+	}
+
+	f := construct.File(infoss, args.Pkg, pkgsrc, importpkg)
+
+	print, err := pretty.Print(f)
+	if err != nil {
+		return fmt.Errorf("pretty printing: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(out), 0o755); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+	fh, err := os.Create(out)
+	if err != nil {
+		return fmt.Errorf("creating output file: %w", err)
+	}
+	defer fh.Close()
+	_, err = io.Copy(fh, print)
+	if err != nil {
+		return fmt.Errorf("writing to output file: %w", err)
+	}
+
+	return nil
+}
+
+// generate inspects the package in dir and writes its client file
+func generate(args Args, dir, out, importpkg string, matched bool) ([]inspects.Diagnostic, error) {
+	inspect := args.Conventions.Dir
+	if args.Types {
+		inspect = args.Conventions.Load
+	}
+
+	pkg, err := inspect(dir)
+	if err != nil {
+		return nil, fmt.Errorf("inspecting files: %w", err)
+	}
+	report.Diagnostics(os.Stderr, pkg.Diagnostics, args.Verbose)
+	if args.Verbose {
+		report.Handlers(os.Stdout, pkg.Handlers)
+	}
+
+	return pkg.Diagnostics, Generate(args, pkg, dir, out, importpkg, matched)
+}
+
+// ImportPath returns the import path of the package declares binding types
+// for targets resolved from patterns when the client is in another directory
+func ImportPath(t targets.Target, importpkg string, matched bool) string {
+	if matched && filepath.Dir(t.Out) != filepath.Clean(t.Dir) {
+		return t.Path
+	}
+	return importpkg
 }
 
 func Main() error {
 	args := Args{}
-	flag.StringVar(&args.Dir, "dir", "", "input directory")
-	flag.StringVar(&args.Out, "out", "", "output file (probably in a \"client\" folder)")
+	flag.StringVar(&args.Dir, "dir", "", "input directory or a package pattern (eg. ./services/...)")
+	flag.StringVar(&args.Out, "out", "", "output file (probably in a \"client\" folder); relative to each package directory when -dir is a pattern")
 	flag.StringVar(&args.Pkg, "pkg", "", "package name for the generated file")
-	flag.StringVar(&args.Import, "import", "", "the import path of package declares binding types")
+	flag.StringVar(&args.Import, "import", "", "the import path of package declares binding types; resolved for each package when -dir is a pattern")
+	flag.BoolVar(&args.Types, "typecheck", false, "inspect the package with full type information")
+	flag.StringVar(&args.Config, "config", "", fmt.Sprintf("the config file to read the conventions from (default %q at the module root, if any)", config.Filename))
 	flag.BoolVar(&args.Verbose, "v", false, "prints additional information")
 	flag.Parse()
 
@@ -33,26 +105,34 @@ func Main() error {
 		return fmt.Errorf("invalid arguments")
 	}
 
-	infoss, pkgsrc, err := inspects.Dir(args.Dir, args.Verbose)
+	conv, path, err := config.Default(args.Config)
 	if err != nil {
-		return fmt.Errorf("inspecting files: %w", err)
+		return fmt.Errorf("reading conventions: %w", err)
 	}
+	if args.Config == "" && path != "" {
+		fmt.Fprintf(os.Stderr, "using the conventions in %s\n", path)
+	}
+	args.Conventions = conv
 
-	f := construct.File(infoss, args.Pkg, pkgsrc, args.Import)
-
-	print, err := pretty.Print(f)
-	if err != nil {
-		return fmt.Errorf("pretty printing: %w", err)
+	matched := inspects.IsPattern(args.Dir)
+	if matched && args.Import != "" {
+		flag.PrintDefaults()
+		return fmt.Errorf("-import can't be used when -dir is a pattern")
 	}
-	fh, err := os.Create(args.Out)
+
+	ts, err := targets.List(args.Dir, args.Out, false)
 	if err != nil {
-		return fmt.Errorf("creating output file: %w", err)
+		return fmt.Errorf("resolving targets: %w", err)
 	}
-	defer fh.Close()
-	_, err = io.Copy(fh, print)
-	if err != nil {
-		return fmt.Errorf("writing to output file: %w", err)
+
+	failing := 0
+	for _, t := range ts {
+		ds, err := generate(args, t.Dir, t.Out, ImportPath(t, args.Import, matched), matched)
+		if err != nil {
+			return fmt.Errorf("%s: %w", t, err)
+		}
+		failing += report.Failing(ds, false)
 	}
 
-	return nil
+	return report.Fail(failing, false)
 }
@@ -0,0 +1,199 @@
+package generate
+
+import (
+	"cmp"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.ufukty.com/gohandlers/cmd/gohandlers/commands/client"
+	"go.ufukty.com/gohandlers/cmd/gohandlers/commands/helpers"
+	"go.ufukty.com/gohandlers/cmd/gohandlers/commands/yaml"
+	"go.ufukty.com/gohandlers/cmd/gohandlers/internal/config"
+	"go.ufukty.com/gohandlers/cmd/gohandlers/internal/report"
+	"go.ufukty.com/gohandlers/cmd/gohandlers/internal/targets"
+	"go.ufukty.com/gohandlers/pkg/inspects"
+)
+
+type Args struct {
+	Config  string
+	Verbose bool
+
+	Diagnostics    string
+	DiagnosticsOut string
+	Werror         bool
+}
+
+// inspector inspects each package once for all generators of a target
+// and reports the diagnostics once
+type inspector struct {
+	conv        inspects.Conventions
+	verbose     bool
+	pkgs        map[string]*inspects.Package
+	diagnostics []inspects.Diagnostic
+}
+
+func (in *inspector) inspect(dir string, types bool) (*inspects.Package, error) {
+	key := fmt.Sprintf("%s:%t", dir, types)
+	if pkg, ok := in.pkgs[key]; ok {
+		return pkg, nil
+	}
+	inspect := in.conv.Dir
+	if types {
+		inspect = in.conv.Load
+	}
+	pkg, err := inspect(dir)
+	if err != nil {
+		return nil, fmt.Errorf("inspecting the directory: %w", err)
+	}
+	report.Diagnostics(os.Stderr, pkg.Diagnostics, in.verbose)
+	if in.verbose {
+		report.Handlers(os.Stdout, pkg.Handlers)
+	}
+	in.pkgs[key] = pkg
+	in.diagnostics = append(in.diagnostics, pkg.Diagnostics...)
+	return pkg, nil
+}
+
+// resolve makes the directory of the target relative to root, the
+// directory of the config file, unless it is absolute or an import path
+// pattern, which go list resolves
+func resolve(root string, t config.Target) config.Target {
+	if filepath.IsAbs(t.Dir) || inspects.IsPattern(t.Dir) && !strings.HasPrefix(t.Dir, ".") {
+		return t
+	}
+	t.Dir = filepath.Join(root, t.Dir)
+	return t
+}
+
+// run runs the generators of the target. Output paths are relative to
+// each package directory.
+func (in *inspector) run(t config.Target) error {
+	matched := inspects.IsPattern(t.Dir)
+
+	if t.Helpers != nil {
+		ts, err := targets.List(t.Dir, cmp.Or(t.Helpers.Out, "gh.go"), true)
+		if err != nil {
+			return fmt.Errorf("resolving helpers targets: %w", err)
+		}
+		args := &helpers.Args{Recv: t.Helpers.Recv, PkgName: t.Helpers.Pkg, Verbose: in.verbose}
+		for _, pt := range ts {
+			pkg, err := in.inspect(pt.Dir, t.Typecheck)
+			if err != nil {
+				return fmt.Errorf("%s: %w", pt, err)
+			}
+			if err := helpers.Generate(args, pkg, pt.Dir, pt.Out, matched); err != nil {
+				return fmt.Errorf("%s: helpers: %w", pt, err)
+			}
+		}
+	}
+
+	if t.Client != nil {
+		ts, err := targets.List(t.Dir, t.Client.Out, true)
+		if err != nil {
+			return fmt.Errorf("resolving client targets: %w", err)
+		}
+		args := client.Args{Pkg: t.Client.Pkg, Verbose: in.verbose}
+		for _, pt := range ts {
+			pkg, err := in.inspect(pt.Dir, t.Typecheck)
+			if err != nil {
+				return fmt.Errorf("%s: %w", pt, err)
+			}
+			importpkg := client.ImportPath(pt, t.Client.Import, matched)
+			if importpkg == "" && filepath.Dir(pt.Out) != filepath.Clean(pt.Dir) {
+				if importpkg, err = targets.ImportPath(pt.Dir); err != nil {
+					return fmt.Errorf("%s: %w", pt, err)
+				}
+			}
+			if err := client.Generate(args, pkg, pt.Dir, pt.Out, importpkg, matched); err != nil {
+				return fmt.Errorf("%s: client: %w", pt, err)
+			}
+		}
+	}
+
+	if t.Yaml != nil {
+		ts, err := targets.List(t.Dir, cmp.Or(t.Yaml.Out, "gh.yml"), true)
+		if err != nil {
+			return fmt.Errorf("resolving yaml targets: %w", err)
+		}
+		args := yaml.Args{Verbose: in.verbose}
+		for _, pt := range ts {
+			pkg, err := in.inspect(pt.Dir, t.Typecheck)
+			if err != nil {
+				return fmt.Errorf("%s: %w", pt, err)
+			}
+			if err := yaml.Generate(args, pkg, pt.Dir, pt.Out, matched); err != nil {
+				return fmt.Errorf("%s: yaml: %w", pt, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func Main() error {
+	args := Args{}
+	flag.StringVar(&args.Config, "config", "", fmt.Sprintf("the config file (default %q at the module root)", config.Filename))
+	flag.BoolVar(&args.Verbose, "v", false, "prints additional information")
+	flag.StringVar(&args.Diagnostics, "diagnostics", "", fmt.Sprintf("also write diagnostics into a file in one of formats: %s", strings.Join(report.Formats, ", ")))
+	flag.StringVar(&args.DiagnosticsOut, "diagnostics-out", "", "the path for diagnostics file (default \"gohandlers.<format>\")")
+	flag.BoolVar(&args.Werror, "Werror", false, "exit with non-zero status also when there are warnings")
+	flag.Parse()
+
+	if args.Diagnostics != "" && !slices.Contains(report.Formats, args.Diagnostics) {
+		flag.PrintDefaults()
+		return fmt.Errorf("unknown diagnostics format: %s", args.Diagnostics)
+	}
+
+	if args.Config == "" {
+		path, err := config.Find()
+		if err != nil {
+			return fmt.Errorf("looking for the config file: %w", err)
+		}
+		if path == "" {
+			return fmt.Errorf("no %s found at the module root", config.Filename)
+		}
+		args.Config = path
+	}
+
+	return Run(args)
+}
+
+// Run runs the targets of the config file. Error diagnostics fail it after
+// the files are generated, and so do warnings with args.Werror.
+func Run(args Args) error {
+	c, err := config.Read(args.Config)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", args.Config, err)
+	}
+	conv, err := c.Conventions.Inspects()
+	if err != nil {
+		return fmt.Errorf("reading conventions: %w", err)
+	}
+
+	in := &inspector{conv: conv, verbose: args.Verbose, pkgs: map[string]*inspects.Package{}}
+	var failed error
+	for _, t := range c.Targets {
+		if err := in.run(resolve(filepath.Dir(args.Config), t)); err != nil {
+			failed = fmt.Errorf("%s: %w", t.Dir, err)
+			break
+		}
+	}
+
+	// written also when a target fails, for the diagnostics found so far
+	if args.Diagnostics != "" {
+		out := cmp.Or(args.DiagnosticsOut, "gohandlers."+args.Diagnostics)
+		if err := report.WriteFile(out, args.Diagnostics, in.diagnostics); err != nil {
+			return errors.Join(failed, err)
+		}
+	}
+	if failed != nil {
+		return failed
+	}
+
+	return report.Fail(report.Failing(in.diagnostics, args.Werror), args.Werror)
+}
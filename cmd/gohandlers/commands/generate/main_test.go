@@ -0,0 +1,121 @@
+package generate
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.ufukty.com/gohandlers/pkg/inspects"
+)
+
+// fixture writes the files into a temporary directory
+func fixture(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("prep: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("prep: %v", err)
+		}
+	}
+	return dir
+}
+
+const conflicts = `package pets
+
+import "net/http"
+
+type Pets struct{}
+
+// GET /pets/{id}
+func (p *Pets) Get(w http.ResponseWriter, r *http.Request) {}
+
+// GET /pets/{id}
+func (p *Pets) Visit(w http.ResponseWriter, r *http.Request) {}
+`
+
+func TestRun_errors(t *testing.T) {
+	dir := fixture(t, map[string]string{
+		"gohandlers.yml": "targets:\n  - dir: pets\n    helpers: {}\n",
+		"pets/pets.go":   conflicts,
+	})
+	err := Run(Args{Config: filepath.Join(dir, "gohandlers.yml")})
+	if err == nil || err.Error() != "found 1 errors" {
+		t.Errorf("expected the route conflict to fail the command, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pets", "gh.go")); err != nil {
+		t.Errorf("expected the file to be generated: %v", err)
+	}
+}
+
+func TestRun_diagnosticsFile(t *testing.T) {
+	dir := fixture(t, map[string]string{
+		"gohandlers.yml": "targets:\n  - dir: pets\n    helpers: {}\n  - dir: missing\n    helpers: {}\n",
+		"pets/pets.go":   conflicts,
+	})
+	out := filepath.Join(dir, "gohandlers.json")
+	err := Run(Args{Config: filepath.Join(dir, "gohandlers.yml"), Diagnostics: "json", DiagnosticsOut: out})
+	if err == nil || !strings.HasPrefix(err.Error(), "missing: ") {
+		t.Errorf("expected the missing directory to fail the command, got %v", err)
+	}
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatalf("expected the diagnostics file to be written: %v", err)
+	}
+	defer f.Close()
+	ds := []map[string]any{}
+	if err := json.NewDecoder(f).Decode(&ds); err != nil {
+		t.Fatalf("decoding the diagnostics file: %v", err)
+	}
+	if len(ds) != 1 || ds[0]["code"] != inspects.CodeRouteConflict {
+		t.Errorf("expected the diagnostics of pets, got %v", ds)
+	}
+}
+
+const pets = `package pets
+
+import "net/http"
+
+type Pets struct{}
+
+// GET /pets/{id}
+func (p *Pets) Get(w http.ResponseWriter, r *http.Request) {}
+`
+
+// TestRun_targets runs the config in another directory, whose targets are
+// relative to the config file rather than the working directory
+func TestRun_targets(t *testing.T) {
+	dir := fixture(t, map[string]string{
+		"gohandlers.yml": "targets:\n" +
+			"  - dir: pets\n" +
+			"    helpers: {}\n" +
+			"    yaml:\n" +
+			"      out: pets.yml\n" +
+			"    client:\n" +
+			"      out: client.go\n" +
+			"      pkg: pets\n",
+		"pets/pets.go": pets,
+	})
+	wd := t.TempDir()
+	t.Chdir(wd)
+
+	if err := Run(Args{Config: filepath.Join(dir, "gohandlers.yml")}); err != nil {
+		t.Fatalf("act: %v", err)
+	}
+	for _, out := range []string{"gh.go", "pets.yml", "client.go"} {
+		if _, err := os.Stat(filepath.Join(dir, "pets", out)); err != nil {
+			t.Errorf("expected %s to be generated: %v", out, err)
+		}
+	}
+	if got, err := os.Getwd(); err != nil || got != wd {
+		t.Errorf("expected the working directory to stay at %s, got %s", wd, got)
+	}
+	if es, err := os.ReadDir(wd); err != nil || len(es) != 0 {
+		t.Errorf("expected nothing to be generated in the working directory, got %v", es)
+	}
+}
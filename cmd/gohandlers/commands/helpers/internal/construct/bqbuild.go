@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
+	"slices"
 
 	"go.ufukty.com/gohandlers/internal/sorted"
 	"go.ufukty.com/gohandlers/pkg/inspects"
@@ -13,6 +14,8 @@ type bqBuildSymbolTable struct {
 	err     bool
 	encoded bool
 	ok      bool
+	values  bool
+	object  bool
 }
 
 type bqBuild struct {
@@ -26,15 +29,34 @@ func ternary[T any](cond bool, t, f T) T {
 	return f
 }
 
+// escape escapes the encoded route value for the wildcard. Values of
+// multi-segment wildcards keep their slashes.
+func escape(seg inspects.Segment) ast.Expr {
+	if seg.Multi {
+		return &ast.CallExpr{
+			Fun:  &ast.Ident{Name: "escapeSegments"},
+			Args: []ast.Expr{&ast.Ident{Name: "encoded"}},
+		}
+	}
+	return &ast.CallExpr{
+		Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "url"}, Sel: &ast.Ident{Name: "PathEscape"}},
+		Args: []ast.Expr{&ast.Ident{Name: "encoded"}},
+	}
+}
+
 func (p *bqBuild) route(info inspects.Info) []ast.Stmt {
 	stmts := []ast.Stmt{}
 	for rp, fn := range sorted.ByValues(info.RequestType.Params.Route) {
+		seg, ok := info.Pattern.Wildcard(rp)
+		if !ok {
+			continue
+		}
 		stmts = append(stmts,
 			&ast.AssignStmt{
 				Lhs: []ast.Expr{&ast.Ident{Name: "encoded"}, &ast.Ident{Name: "err"}},
 				Tok: ternary(p.table.encoded && p.table.err, token.ASSIGN, token.DEFINE),
 				Rhs: []ast.Expr{&ast.CallExpr{Fun: &ast.SelectorExpr{
-					X:   &ast.SelectorExpr{X: &ast.Ident{Name: "bq"}, Sel: &ast.Ident{Name: fn}},
+					X:   field("bq", fn),
 					Sel: &ast.Ident{Name: "ToRoute"},
 				}}},
 			},
@@ -61,8 +83,8 @@ func (p *bqBuild) route(info inspects.Info) []ast.Stmt {
 						Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "strings"}, Sel: &ast.Ident{Name: "Replace"}},
 						Args: []ast.Expr{
 							&ast.Ident{Name: "uri"},
-							&ast.BasicLit{Kind: token.STRING, Value: quotes(fmt.Sprintf("{%s}", rp))},
-							&ast.Ident{Name: "encoded"},
+							&ast.BasicLit{Kind: token.STRING, Value: quotes(seg.Placeholder())},
+							escape(seg),
 							&ast.BasicLit{Kind: token.INT, Value: "1"},
 						},
 					},
@@ -82,11 +104,19 @@ func (p *bqBuild) query(info inspects.Info) []ast.Stmt {
 			&ast.AssignStmt{
 				Lhs: []ast.Expr{&ast.Ident{Name: "q"}},
 				Tok: token.DEFINE,
-				Rhs: []ast.Expr{&ast.CompositeLit{Type: &ast.ArrayType{Elt: &ast.Ident{Name: "string"}}}},
+				Rhs: []ast.Expr{&ast.CompositeLit{Type: &ast.SelectorExpr{X: &ast.Ident{Name: "url"}, Sel: &ast.Ident{Name: "Values"}}}},
 			},
 		)
 
 		for qp, fn := range sorted.ByValues(info.RequestType.Params.Query) {
+			if enc := info.RequestType.Arrays[qp]; enc != "" {
+				stmts = append(stmts, p.queryValues(info, qp, fn, enc)...)
+				continue
+			}
+			if info.RequestType.Objects[qp] {
+				stmts = append(stmts, p.queryObject(info, qp, fn)...)
+				continue
+			}
 			stmts = append(stmts,
 				&ast.AssignStmt{
 					Lhs: []ast.Expr{&ast.Ident{Name: "encoded"}, &ast.Ident{Name: "ok"}, &ast.Ident{Name: "err"}},
@@ -94,7 +124,7 @@ func (p *bqBuild) query(info inspects.Info) []ast.Stmt {
 					Rhs: []ast.Expr{
 						&ast.CallExpr{
 							Fun: &ast.SelectorExpr{
-								X:   &ast.SelectorExpr{X: &ast.Ident{Name: "bq"}, Sel: &ast.Ident{Name: fn}},
+								X:   field("bq", fn),
 								Sel: &ast.Ident{Name: "ToQuery"},
 							},
 						},
@@ -120,25 +150,13 @@ func (p *bqBuild) query(info inspects.Info) []ast.Stmt {
 				&ast.IfStmt{
 					Cond: &ast.Ident{Name: "ok"},
 					Body: &ast.BlockStmt{List: []ast.Stmt{
-						&ast.AssignStmt{
-							Lhs: []ast.Expr{&ast.Ident{Name: "q"}},
-							Tok: token.ASSIGN,
-							Rhs: []ast.Expr{
-								&ast.CallExpr{
-									Fun: &ast.Ident{Name: "append"},
-									Args: []ast.Expr{
-										&ast.Ident{Name: "q"},
-										&ast.CallExpr{
-											Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "fmt"}, Sel: &ast.Ident{Name: "Sprintf"}},
-											Args: []ast.Expr{
-												&ast.BasicLit{Kind: token.STRING, Value: quotes(fmt.Sprintf("%s=%%s", qp))},
-												&ast.Ident{Name: "encoded"},
-											},
-										},
-									},
-								},
+						&ast.ExprStmt{X: &ast.CallExpr{
+							Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "q"}, Sel: &ast.Ident{Name: "Set"}},
+							Args: []ast.Expr{
+								&ast.BasicLit{Kind: token.STRING, Value: quotes(qp)},
+								&ast.Ident{Name: "encoded"},
 							},
-						},
+						}},
 					}},
 				},
 			)
@@ -164,13 +182,7 @@ func (p *bqBuild) query(info inspects.Info) []ast.Stmt {
 								Args: []ast.Expr{
 									&ast.BasicLit{Kind: token.STRING, Value: `"%s?%s"`},
 									&ast.Ident{Name: "uri"},
-									&ast.CallExpr{
-										Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "strings"}, Sel: &ast.Ident{Name: "Join"}},
-										Args: []ast.Expr{
-											&ast.Ident{Name: "q"},
-											&ast.BasicLit{Kind: token.STRING, Value: quotes("&")},
-										},
-									},
+									&ast.CallExpr{Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "q"}, Sel: &ast.Ident{Name: "Encode"}}},
 								},
 							},
 						},
@@ -183,9 +195,155 @@ func (p *bqBuild) query(info inspects.Info) []ast.Stmt {
 	return stmts
 }
 
+// queryValues adds all values of the field to the query, either in
+// repeated params or joined with commas
+func (p *bqBuild) queryValues(info inspects.Info, qp, fn, enc string) []ast.Stmt {
+	var add ast.Stmt = &ast.AssignStmt{
+		Lhs: []ast.Expr{&ast.IndexExpr{X: &ast.Ident{Name: "q"}, Index: &ast.BasicLit{Kind: token.STRING, Value: quotes(qp)}}},
+		Tok: token.ASSIGN,
+		Rhs: []ast.Expr{&ast.Ident{Name: "values"}},
+	}
+	if enc == "comma" {
+		add = &ast.ExprStmt{X: &ast.CallExpr{
+			Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "q"}, Sel: &ast.Ident{Name: "Set"}},
+			Args: []ast.Expr{
+				&ast.BasicLit{Kind: token.STRING, Value: quotes(qp)},
+				&ast.CallExpr{
+					Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "strings"}, Sel: &ast.Ident{Name: "Join"}},
+					Args: []ast.Expr{&ast.Ident{Name: "values"}, &ast.BasicLit{Kind: token.STRING, Value: quotes(",")}},
+				},
+			},
+		}}
+	}
+	stmts := []ast.Stmt{
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{&ast.Ident{Name: "values"}, &ast.Ident{Name: "err"}},
+			Tok: ternary(p.table.values && p.table.err, token.ASSIGN, token.DEFINE),
+			Rhs: []ast.Expr{&ast.CallExpr{Fun: &ast.SelectorExpr{X: field("bq", fn), Sel: &ast.Ident{Name: "ToQueryValues"}}}},
+		},
+		&ast.IfStmt{
+			Cond: &ast.BinaryExpr{X: &ast.Ident{Name: "err"}, Op: token.NEQ, Y: &ast.Ident{Name: "nil"}},
+			Body: &ast.BlockStmt{List: []ast.Stmt{&ast.ReturnStmt{Results: []ast.Expr{
+				&ast.Ident{Name: "nil"},
+				&ast.CallExpr{
+					Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "fmt"}, Sel: &ast.Ident{Name: "Errorf"}},
+					Args: []ast.Expr{
+						&ast.BasicLit{Kind: token.STRING, Value: quotes(fmt.Sprintf("%s.%s.ToQueryValues: %%w", info.RequestType.Typename, fn))},
+						&ast.Ident{Name: "err"},
+					},
+				},
+			}}}},
+		},
+	}
+	if enc == "comma" {
+		// otherwise the value is split into two on the server
+		stmts = append(stmts, &ast.RangeStmt{
+			Key:   &ast.Ident{Name: "_"},
+			Value: &ast.Ident{Name: "v"},
+			Tok:   token.DEFINE,
+			X:     &ast.Ident{Name: "values"},
+			Body: &ast.BlockStmt{List: []ast.Stmt{&ast.IfStmt{
+				Cond: &ast.CallExpr{
+					Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "strings"}, Sel: &ast.Ident{Name: "Contains"}},
+					Args: []ast.Expr{&ast.Ident{Name: "v"}, &ast.BasicLit{Kind: token.STRING, Value: quotes(",")}},
+				},
+				Body: &ast.BlockStmt{List: []ast.Stmt{&ast.ReturnStmt{Results: []ast.Expr{
+					&ast.Ident{Name: "nil"},
+					&ast.CallExpr{
+						Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "fmt"}, Sel: &ast.Ident{Name: "Errorf"}},
+						Args: []ast.Expr{
+							&ast.BasicLit{Kind: token.STRING, Value: quotes(fmt.Sprintf("%s.%s: value %%q contains a comma, which separates the values", info.RequestType.Typename, fn))},
+							&ast.Ident{Name: "v"},
+						},
+					},
+				}}}},
+			}}},
+		})
+	}
+	stmts = append(stmts,
+		&ast.IfStmt{
+			Cond: &ast.BinaryExpr{
+				X:  &ast.CallExpr{Fun: &ast.Ident{Name: "len"}, Args: []ast.Expr{&ast.Ident{Name: "values"}}},
+				Op: token.GTR,
+				Y:  &ast.BasicLit{Kind: token.INT, Value: "0"},
+			},
+			Body: &ast.BlockStmt{List: []ast.Stmt{add}},
+		},
+	)
+	p.table.values = true
+	p.table.err = true
+	return stmts
+}
+
+// queryObject adds each key of the field to the query as "param[key]"
+func (p *bqBuild) queryObject(info inspects.Info, qp, fn string) []ast.Stmt {
+	stmts := []ast.Stmt{
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{&ast.Ident{Name: "object"}, &ast.Ident{Name: "err"}},
+			Tok: ternary(p.table.object && p.table.err, token.ASSIGN, token.DEFINE),
+			Rhs: []ast.Expr{&ast.CallExpr{Fun: &ast.SelectorExpr{X: field("bq", fn), Sel: &ast.Ident{Name: "ToQueryObject"}}}},
+		},
+		&ast.IfStmt{
+			Cond: &ast.BinaryExpr{X: &ast.Ident{Name: "err"}, Op: token.NEQ, Y: &ast.Ident{Name: "nil"}},
+			Body: &ast.BlockStmt{List: []ast.Stmt{&ast.ReturnStmt{Results: []ast.Expr{
+				&ast.Ident{Name: "nil"},
+				&ast.CallExpr{
+					Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "fmt"}, Sel: &ast.Ident{Name: "Errorf"}},
+					Args: []ast.Expr{
+						&ast.BasicLit{Kind: token.STRING, Value: quotes(fmt.Sprintf("%s.%s.ToQueryObject: %%w", info.RequestType.Typename, fn))},
+						&ast.Ident{Name: "err"},
+					},
+				},
+			}}}},
+		},
+		&ast.RangeStmt{
+			Key:   &ast.Ident{Name: "k"},
+			Value: &ast.Ident{Name: "v"},
+			Tok:   token.DEFINE,
+			X:     &ast.Ident{Name: "object"},
+			Body: &ast.BlockStmt{List: []ast.Stmt{
+				&ast.ExprStmt{X: &ast.CallExpr{
+					Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "q"}, Sel: &ast.Ident{Name: "Set"}},
+					Args: []ast.Expr{
+						&ast.BinaryExpr{
+							X: &ast.BinaryExpr{
+								X:  &ast.BasicLit{Kind: token.STRING, Value: quotes(qp + "[")},
+								Op: token.ADD,
+								Y:  &ast.Ident{Name: "k"},
+							},
+							Op: token.ADD,
+							Y:  &ast.BasicLit{Kind: token.STRING, Value: quotes("]")},
+						},
+						&ast.Ident{Name: "v"},
+					},
+				}},
+			}},
+		},
+	}
+	p.table.object = true
+	p.table.err = true
+	return stmts
+}
+
 func (p *bqBuild) body(info inspects.Info) []ast.Stmt {
 	stmts := []ast.Stmt{}
-	if info.RequestType.ContentType != "" {
+	if multipart(info.RequestType) {
+		stmts = append(stmts,
+			&ast.AssignStmt{
+				Lhs: []ast.Expr{&ast.Ident{Name: "body"}, &ast.Ident{Name: "pw"}},
+				Tok: token.DEFINE,
+				Rhs: []ast.Expr{&ast.CallExpr{Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "io"}, Sel: &ast.Ident{Name: "Pipe"}}}},
+			},
+			&ast.AssignStmt{
+				Lhs: []ast.Expr{&ast.Ident{Name: "mw"}},
+				Tok: token.DEFINE,
+				Rhs: []ast.Expr{&ast.CallExpr{
+					Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "multipart"}, Sel: &ast.Ident{Name: "NewWriter"}},
+					Args: []ast.Expr{&ast.Ident{Name: "pw"}},
+				}},
+			},
+		)
+	} else if info.RequestType.ContentType != "" && info.RequestType.Body == "" {
 		stmts = append(stmts, &ast.AssignStmt{
 			Lhs: []ast.Expr{&ast.Ident{Name: "body"}},
 			Tok: token.DEFINE,
@@ -198,6 +356,55 @@ func (p *bqBuild) body(info inspects.Info) []ast.Stmt {
 	return stmts
 }
 
+// form writes the urlencoded form fields into the body
+func (p *bqBuild) form(info inspects.Info) []ast.Stmt {
+	stmts := []ast.Stmt{}
+	if len(info.RequestType.Params.Form) == 0 || multipart(info.RequestType) {
+		return stmts
+	}
+	stmts = append(stmts, &ast.AssignStmt{
+		Lhs: []ast.Expr{&ast.Ident{Name: "form"}},
+		Tok: token.DEFINE,
+		Rhs: []ast.Expr{&ast.CompositeLit{Type: &ast.SelectorExpr{X: &ast.Ident{Name: "url"}, Sel: &ast.Ident{Name: "Values"}}}},
+	})
+	for fp, fn := range sorted.ByValues(info.RequestType.Params.Form) {
+		stmts = append(stmts,
+			&ast.AssignStmt{
+				Lhs: []ast.Expr{&ast.Ident{Name: "encoded"}, &ast.Ident{Name: "err"}},
+				Tok: ternary(p.table.encoded && p.table.err, token.ASSIGN, token.DEFINE),
+				Rhs: []ast.Expr{&ast.CallExpr{Fun: &ast.SelectorExpr{X: field("bq", fn), Sel: &ast.Ident{Name: "ToForm"}}}},
+			},
+			&ast.IfStmt{
+				Cond: &ast.BinaryExpr{X: &ast.Ident{Name: "err"}, Op: token.NEQ, Y: &ast.Ident{Name: "nil"}},
+				Body: &ast.BlockStmt{List: []ast.Stmt{&ast.ReturnStmt{Results: []ast.Expr{
+					&ast.Ident{Name: "nil"},
+					&ast.CallExpr{
+						Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "fmt"}, Sel: &ast.Ident{Name: "Errorf"}},
+						Args: []ast.Expr{
+							&ast.BasicLit{Kind: token.STRING, Value: quotes(fmt.Sprintf("%s.%s.ToForm: %%w", info.RequestType.Typename, fn))},
+							&ast.Ident{Name: "err"},
+						},
+					},
+				}}}},
+			},
+			&ast.ExprStmt{X: &ast.CallExpr{
+				Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "form"}, Sel: &ast.Ident{Name: "Set"}},
+				Args: []ast.Expr{
+					&ast.BasicLit{Kind: token.STRING, Value: quotes(fp)},
+					&ast.Ident{Name: "encoded"},
+				},
+			}},
+		)
+		p.table.encoded = true
+		p.table.err = true
+	}
+	stmts = append(stmts, &ast.ExprStmt{X: &ast.CallExpr{
+		Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "body"}, Sel: &ast.Ident{Name: "WriteString"}},
+		Args: []ast.Expr{&ast.CallExpr{Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "form"}, Sel: &ast.Ident{Name: "Encode"}}}},
+	}})
+	return stmts
+}
+
 func (p *bqBuild) json(info inspects.Info) []ast.Stmt {
 	stmts := []ast.Stmt{}
 	if len(info.RequestType.Params.Json) > 0 {
@@ -214,7 +421,7 @@ func (p *bqBuild) json(info inspects.Info) []ast.Stmt {
 							},
 							Sel: &ast.Ident{Name: "Encode"},
 						},
-						Args: []ast.Expr{&ast.Ident{Name: "bq"}},
+						Args: []ast.Expr{ternary(info.RequestType.Viewed(), view(info.RequestType, "bq"), ast.Expr(&ast.Ident{Name: "bq"}))},
 					}},
 				},
 				Cond: &ast.BinaryExpr{X: &ast.Ident{Name: "err"}, Op: token.NEQ, Y: &ast.Ident{Name: "nil"}},
@@ -235,6 +442,37 @@ func (p *bqBuild) json(info inspects.Info) []ast.Stmt {
 	return stmts
 }
 
+// multipart starts writing the form and file fields into the body. The
+// writer blocks until the body is read and stops when it is closed. It is
+// started last, so it isn't left blocked when building fails.
+func (p *bqBuild) multipart(info inspects.Info) []ast.Stmt {
+	if !multipart(info.RequestType) {
+		return []ast.Stmt{}
+	}
+	return []ast.Stmt{&ast.GoStmt{Call: &ast.CallExpr{Fun: &ast.FuncLit{
+		Type: &ast.FuncType{Params: &ast.FieldList{}},
+		Body: &ast.BlockStmt{List: []ast.Stmt{&ast.ExprStmt{X: &ast.CallExpr{
+			Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "pw"}, Sel: &ast.Ident{Name: "CloseWithError"}},
+			Args: []ast.Expr{&ast.CallExpr{
+				Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "bq"}, Sel: &ast.Ident{Name: "marshalFormData"}},
+				Args: []ast.Expr{&ast.Ident{Name: "mw"}},
+			}},
+		}}}},
+	}}}}
+}
+
+// requestBody is the body of the request, which is the raw body field
+// when there is one
+func requestBody(bti *inspects.BindingTypeInfo) ast.Expr {
+	switch {
+	case bti.Body != "":
+		return field("bq", bti.Body)
+	case bti.ContainsBody:
+		return &ast.Ident{Name: "body"}
+	}
+	return &ast.Ident{Name: "nil"}
+}
+
 func (p *bqBuild) request(info inspects.Info) []ast.Stmt {
 	stmts := []ast.Stmt{}
 	stmts = append(stmts,
@@ -249,7 +487,7 @@ func (p *bqBuild) request(info inspects.Info) []ast.Stmt {
 						Fun:  &ast.Ident{Name: "join"},
 						Args: []ast.Expr{&ast.Ident{Name: "host"}, &ast.Ident{Name: "uri"}},
 					},
-					&ast.Ident{Name: ternary(info.RequestType.ContainsBody, "body", "nil")},
+					requestBody(info.RequestType),
 				},
 			}},
 		},
@@ -269,8 +507,160 @@ func (p *bqBuild) request(info inspects.Info) []ast.Stmt {
 	return stmts
 }
 
+// header adds the values of header fields to the request. Fields can
+// encode into multiple values, each is added as a separate header line.
+func (p *bqBuild) header(info inspects.Info) []ast.Stmt {
+	stmts := []ast.Stmt{}
+	for hp, fn := range sorted.ByValues(info.RequestType.Params.Header) {
+		stmts = append(stmts,
+			&ast.AssignStmt{
+				Lhs: []ast.Expr{&ast.Ident{Name: "values"}, &ast.Ident{Name: "err"}},
+				Tok: ternary(p.table.values, token.ASSIGN, token.DEFINE),
+				Rhs: []ast.Expr{&ast.CallExpr{Fun: &ast.SelectorExpr{
+					X:   field("bq", fn),
+					Sel: &ast.Ident{Name: "ToHeader"},
+				}}},
+			},
+			&ast.IfStmt{
+				Cond: &ast.BinaryExpr{X: &ast.Ident{Name: "err"}, Op: token.NEQ, Y: &ast.Ident{Name: "nil"}},
+				Body: &ast.BlockStmt{List: []ast.Stmt{
+					&ast.ReturnStmt{Results: []ast.Expr{
+						&ast.Ident{Name: "nil"},
+						&ast.CallExpr{
+							Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "fmt"}, Sel: &ast.Ident{Name: "Errorf"}},
+							Args: []ast.Expr{
+								&ast.BasicLit{Kind: token.STRING, Value: quotes(fmt.Sprintf("%s.%s.ToHeader: %%w", info.RequestType.Typename, fn))},
+								&ast.Ident{Name: "err"},
+							},
+						},
+					}},
+				}},
+			},
+			&ast.RangeStmt{
+				Key:   &ast.Ident{Name: "_"},
+				Value: &ast.Ident{Name: "v"},
+				Tok:   token.DEFINE,
+				X:     &ast.Ident{Name: "values"},
+				Body: &ast.BlockStmt{List: []ast.Stmt{
+					&ast.ExprStmt{X: &ast.CallExpr{
+						Fun: &ast.SelectorExpr{
+							X:   &ast.SelectorExpr{X: &ast.Ident{Name: "r"}, Sel: &ast.Ident{Name: "Header"}},
+							Sel: &ast.Ident{Name: "Add"},
+						},
+						Args: []ast.Expr{
+							&ast.BasicLit{Kind: token.STRING, Value: quotes(hp)},
+							&ast.Ident{Name: "v"},
+						},
+					}},
+				}},
+			},
+		)
+		p.table.values = true
+	}
+	return stmts
+}
+
+// cookie attaches the cookies of fields that have a value
+func (p *bqBuild) cookie(info inspects.Info) []ast.Stmt {
+	stmts := []ast.Stmt{}
+	for cp, fn := range sorted.ByValues(info.RequestType.Params.Cookie) {
+		stmts = append(stmts,
+			&ast.AssignStmt{
+				Lhs: []ast.Expr{&ast.Ident{Name: "encoded"}, &ast.Ident{Name: "ok"}, &ast.Ident{Name: "err"}},
+				Tok: ternary(p.table.encoded && p.table.ok, token.ASSIGN, token.DEFINE),
+				Rhs: []ast.Expr{&ast.CallExpr{Fun: &ast.SelectorExpr{
+					X:   field("bq", fn),
+					Sel: &ast.Ident{Name: "ToCookie"},
+				}}},
+			},
+			&ast.IfStmt{
+				Cond: &ast.BinaryExpr{X: &ast.Ident{Name: "err"}, Op: token.NEQ, Y: &ast.Ident{Name: "nil"}},
+				Body: &ast.BlockStmt{List: []ast.Stmt{
+					&ast.ReturnStmt{Results: []ast.Expr{
+						&ast.Ident{Name: "nil"},
+						&ast.CallExpr{
+							Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "fmt"}, Sel: &ast.Ident{Name: "Errorf"}},
+							Args: []ast.Expr{
+								&ast.BasicLit{Kind: token.STRING, Value: quotes(fmt.Sprintf("%s.%s.ToCookie: %%w", info.RequestType.Typename, fn))},
+								&ast.Ident{Name: "err"},
+							},
+						},
+					}},
+				}},
+			},
+			&ast.IfStmt{
+				Cond: &ast.Ident{Name: "ok"},
+				Body: &ast.BlockStmt{List: append(
+					newCookie(info.RequestType, fn, cp, []ast.Expr{&ast.Ident{Name: "nil"}}),
+					&ast.ExprStmt{X: &ast.CallExpr{
+						Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "r"}, Sel: &ast.Ident{Name: "AddCookie"}},
+						Args: []ast.Expr{&ast.Ident{Name: "cookie"}},
+					}},
+				)},
+			},
+		)
+		p.table.encoded = true
+		p.table.ok = true
+	}
+	return stmts
+}
+
+// newCookie declares the cookie with the encoded value and returns the error
+// of [http.Cookie.Valid] along with zeros, as net/http would otherwise drop
+// the bytes that can't be in cookie values without telling
+func newCookie(bti *inspects.BindingTypeInfo, fn, cp string, zeros []ast.Expr) []ast.Stmt {
+	return []ast.Stmt{
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{&ast.Ident{Name: "cookie"}},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{&ast.UnaryExpr{Op: token.AND, X: &ast.CompositeLit{
+				Type: &ast.SelectorExpr{X: &ast.Ident{Name: "http"}, Sel: &ast.Ident{Name: "Cookie"}},
+				Elts: []ast.Expr{
+					&ast.KeyValueExpr{Key: &ast.Ident{Name: "Name"}, Value: &ast.BasicLit{Kind: token.STRING, Value: quotes(cp)}},
+					&ast.KeyValueExpr{Key: &ast.Ident{Name: "Value"}, Value: &ast.Ident{Name: "encoded"}},
+				},
+			}}},
+		},
+		&ast.IfStmt{
+			Init: &ast.AssignStmt{
+				Lhs: []ast.Expr{&ast.Ident{Name: "err"}},
+				Tok: token.DEFINE,
+				Rhs: []ast.Expr{&ast.CallExpr{Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "cookie"}, Sel: &ast.Ident{Name: "Valid"}}}},
+			},
+			Cond: &ast.BinaryExpr{X: &ast.Ident{Name: "err"}, Op: token.NEQ, Y: &ast.Ident{Name: "nil"}},
+			Body: &ast.BlockStmt{List: []ast.Stmt{
+				&ast.ReturnStmt{Results: append(slices.Clone(zeros),
+					&ast.CallExpr{
+						Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "fmt"}, Sel: &ast.Ident{Name: "Errorf"}},
+						Args: []ast.Expr{
+							&ast.BasicLit{Kind: token.STRING, Value: quotes(fmt.Sprintf("%s.%s: %%w", bti.Typename, fn))},
+							&ast.Ident{Name: "err"},
+						},
+					},
+				)},
+			}},
+		},
+	}
+}
+
+// contentType is the content type of the request body, which contains the
+// boundary for multipart bodies
+func contentType(bti *inspects.BindingTypeInfo) ast.Expr {
+	if multipart(bti) {
+		return &ast.CallExpr{Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "mw"}, Sel: &ast.Ident{Name: "FormDataContentType"}}}
+	}
+	return &ast.BasicLit{Kind: token.STRING, Value: quotes(bti.ContentType)}
+}
+
 func (p *bqBuild) postRequest(info inspects.Info) []ast.Stmt {
 	stmts := []ast.Stmt{}
+	if info.Pattern.Host != "" {
+		stmts = append(stmts, &ast.AssignStmt{
+			Lhs: []ast.Expr{&ast.SelectorExpr{X: &ast.Ident{Name: "r"}, Sel: &ast.Ident{Name: "Host"}}},
+			Tok: token.ASSIGN,
+			Rhs: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: quotes(info.Pattern.Host)}},
+		})
+	}
 	if info.RequestType.ContainsBody {
 		stmts = append(stmts,
 			&ast.ExprStmt{X: &ast.CallExpr{
@@ -280,9 +670,41 @@ func (p *bqBuild) postRequest(info inspects.Info) []ast.Stmt {
 				},
 				Args: []ast.Expr{
 					&ast.BasicLit{Kind: token.STRING, Value: `"Content-Type"`},
-					&ast.BasicLit{Kind: token.STRING, Value: quotes(info.RequestType.ContentType)},
+					contentType(info.RequestType),
 				},
 			}},
+		)
+	}
+	if info.RequestType.Body != "" {
+		// [http.NewRequest] sets the length for the readers of bytes and
+		// strings packages. Otherwise the body is sent chunked.
+		stmts = append(stmts, &ast.IfStmt{
+			Init: &ast.AssignStmt{
+				Lhs: []ast.Expr{&ast.Ident{Name: "l"}, &ast.Ident{Name: "ok"}},
+				Tok: token.DEFINE,
+				Rhs: []ast.Expr{&ast.TypeAssertExpr{
+					X: field("bq", info.RequestType.Body),
+					Type: &ast.InterfaceType{Methods: &ast.FieldList{List: []*ast.Field{{
+						Names: []*ast.Ident{{Name: "Len"}},
+						Type: &ast.FuncType{
+							Params:  &ast.FieldList{},
+							Results: &ast.FieldList{List: []*ast.Field{{Type: &ast.Ident{Name: "int"}}}},
+						},
+					}}}},
+				}},
+			},
+			Cond: &ast.Ident{Name: "ok"},
+			Body: &ast.BlockStmt{List: []ast.Stmt{&ast.AssignStmt{
+				Lhs: []ast.Expr{&ast.SelectorExpr{X: &ast.Ident{Name: "r"}, Sel: &ast.Ident{Name: "ContentLength"}}},
+				Tok: token.ASSIGN,
+				Rhs: []ast.Expr{&ast.CallExpr{
+					Fun:  &ast.Ident{Name: "int64"},
+					Args: []ast.Expr{&ast.CallExpr{Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "l"}, Sel: &ast.Ident{Name: "Len"}}}},
+				}},
+			}}},
+		})
+	} else if info.RequestType.ContainsBody && !multipart(info.RequestType) {
+		stmts = append(stmts,
 			&ast.ExprStmt{X: &ast.CallExpr{
 				Fun: &ast.SelectorExpr{
 					X:   &ast.SelectorExpr{X: &ast.Ident{Name: "r"}, Sel: &ast.Ident{Name: "Header"}},
@@ -329,7 +751,7 @@ func (p *bqBuild) Produce(info inspects.Info) *ast.FuncDecl {
 		&ast.AssignStmt{
 			Lhs: []ast.Expr{&ast.Ident{Name: "uri"}},
 			Tok: token.DEFINE,
-			Rhs: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: quotes(info.Path)}},
+			Rhs: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: quotes(info.Pattern.Template())}},
 		},
 	)
 
@@ -337,8 +759,12 @@ func (p *bqBuild) Produce(info inspects.Info) *ast.FuncDecl {
 	fd.Body.List = append(fd.Body.List, p.query(info)...)
 	fd.Body.List = append(fd.Body.List, p.body(info)...)
 	fd.Body.List = append(fd.Body.List, p.json(info)...)
+	fd.Body.List = append(fd.Body.List, p.form(info)...)
 	fd.Body.List = append(fd.Body.List, p.request(info)...)
 	fd.Body.List = append(fd.Body.List, p.postRequest(info)...)
+	fd.Body.List = append(fd.Body.List, p.header(info)...)
+	fd.Body.List = append(fd.Body.List, p.cookie(info)...)
+	fd.Body.List = append(fd.Body.List, p.multipart(info)...)
 
 	fd.Body.List = append(fd.Body.List,
 		&ast.ReturnStmt{Results: []ast.Expr{&ast.Ident{Name: "r"}, &ast.Ident{Name: "nil"}}},
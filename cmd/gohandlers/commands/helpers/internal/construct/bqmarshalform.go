@@ -0,0 +1,126 @@
+package construct
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+
+	"go.ufukty.com/gohandlers/internal/sorted"
+	"go.ufukty.com/gohandlers/pkg/inspects"
+)
+
+// BqMarshalFormData produces the method writing the form and file fields
+// into the multipart body. The request builder runs it while the body is
+// read, so the files are streamed instead of buffered.
+func BqMarshalFormData(i inspects.Info) *ast.FuncDecl {
+	fd := &ast.FuncDecl{
+		Recv: &ast.FieldList{List: []*ast.Field{
+			{Names: []*ast.Ident{{Name: "bq"}}, Type: &ast.Ident{Name: i.RequestType.Typename}},
+		}},
+		Name: &ast.Ident{Name: "marshalFormData"},
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{List: []*ast.Field{{
+				Names: []*ast.Ident{{Name: "mw"}},
+				Type:  &ast.StarExpr{X: &ast.SelectorExpr{X: &ast.Ident{Name: "multipart"}, Sel: &ast.Ident{Name: "Writer"}}},
+			}}},
+			Results: &ast.FieldList{List: []*ast.Field{{Type: &ast.Ident{Name: "error"}}}},
+		},
+		Body: &ast.BlockStmt{List: []ast.Stmt{}},
+	}
+
+	ret := func(msg string) *ast.BlockStmt {
+		return &ast.BlockStmt{List: []ast.Stmt{&ast.ReturnStmt{Results: []ast.Expr{&ast.CallExpr{
+			Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "fmt"}, Sel: &ast.Ident{Name: "Errorf"}},
+			Args: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: quotes(msg)}, &ast.Ident{Name: "err"}},
+		}}}}}
+	}
+	failed := &ast.BinaryExpr{X: &ast.Ident{Name: "err"}, Op: token.NEQ, Y: &ast.Ident{Name: "nil"}}
+
+	encoded := false
+	for fp, fn := range sorted.ByValues(i.RequestType.Params.Form) {
+		fd.Body.List = append(fd.Body.List,
+			&ast.AssignStmt{
+				Lhs: []ast.Expr{&ast.Ident{Name: "encoded"}, &ast.Ident{Name: "err"}},
+				Tok: ternary(encoded, token.ASSIGN, token.DEFINE),
+				Rhs: []ast.Expr{&ast.CallExpr{Fun: &ast.SelectorExpr{X: field("bq", fn), Sel: &ast.Ident{Name: "ToForm"}}}},
+			},
+			&ast.IfStmt{Cond: failed, Body: ret(fmt.Sprintf("%s.%s.ToForm: %%w", i.RequestType.Typename, fn))},
+			&ast.IfStmt{
+				Init: &ast.AssignStmt{
+					Lhs: []ast.Expr{&ast.Ident{Name: "err"}},
+					Tok: token.DEFINE,
+					Rhs: []ast.Expr{&ast.CallExpr{
+						Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "mw"}, Sel: &ast.Ident{Name: "WriteField"}},
+						Args: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: quotes(fp)}, &ast.Ident{Name: "encoded"}},
+					}},
+				},
+				Cond: failed,
+				Body: ret("multipart.Writer.WriteField: %w"),
+			},
+		)
+		encoded = true
+	}
+
+	files := false
+	for fp, fn := range sorted.ByValues(i.RequestType.Params.File) {
+		fd.Body.List = append(fd.Body.List,
+			&ast.AssignStmt{
+				Lhs: []ast.Expr{&ast.Ident{Name: "files"}, &ast.Ident{Name: "err"}},
+				Tok: ternary(files, token.ASSIGN, token.DEFINE),
+				Rhs: []ast.Expr{&ast.CallExpr{Fun: &ast.SelectorExpr{X: field("bq", fn), Sel: &ast.Ident{Name: "ToFile"}}}},
+			},
+			&ast.IfStmt{Cond: failed, Body: ret(fmt.Sprintf("%s.%s.ToFile: %%w", i.RequestType.Typename, fn))},
+			&ast.RangeStmt{
+				Key:   &ast.Ident{Name: "_"},
+				Value: &ast.Ident{Name: "f"},
+				Tok:   token.DEFINE,
+				X:     &ast.Ident{Name: "files"},
+				Body: &ast.BlockStmt{List: []ast.Stmt{
+					&ast.AssignStmt{
+						Lhs: []ast.Expr{&ast.Ident{Name: "fw"}, &ast.Ident{Name: "err"}},
+						Tok: token.DEFINE,
+						Rhs: []ast.Expr{&ast.CallExpr{
+							Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "mw"}, Sel: &ast.Ident{Name: "CreateFormFile"}},
+							Args: []ast.Expr{
+								&ast.BasicLit{Kind: token.STRING, Value: quotes(fp)},
+								&ast.SelectorExpr{X: &ast.Ident{Name: "f"}, Sel: &ast.Ident{Name: "Name"}},
+							},
+						}},
+					},
+					&ast.IfStmt{Cond: failed, Body: ret("multipart.Writer.CreateFormFile: %w")},
+					&ast.IfStmt{
+						Init: &ast.AssignStmt{
+							Lhs: []ast.Expr{&ast.Ident{Name: "_"}, &ast.Ident{Name: "err"}},
+							Tok: token.DEFINE,
+							Rhs: []ast.Expr{&ast.CallExpr{
+								Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "io"}, Sel: &ast.Ident{Name: "Copy"}},
+								Args: []ast.Expr{
+									&ast.Ident{Name: "fw"},
+									&ast.SelectorExpr{X: &ast.Ident{Name: "f"}, Sel: &ast.Ident{Name: "Content"}},
+								},
+							}},
+						},
+						Cond: failed,
+						Body: ret(fmt.Sprintf("copying the file of %s.%s: %%w", i.RequestType.Typename, fn)),
+					},
+				}},
+			},
+		)
+		files = true
+	}
+
+	fd.Body.List = append(fd.Body.List,
+		&ast.IfStmt{
+			Init: &ast.AssignStmt{
+				Lhs: []ast.Expr{&ast.Ident{Name: "err"}},
+				Tok: token.DEFINE,
+				Rhs: []ast.Expr{&ast.CallExpr{Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "mw"}, Sel: &ast.Ident{Name: "Close"}}}},
+			},
+			Cond: failed,
+			Body: ret("multipart.Writer.Close: %w"),
+		},
+		&ast.ReturnStmt{Results: []ast.Expr{&ast.Ident{Name: "nil"}}},
+	)
+
+	return fd
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
+	"strings"
 
 	"go.ufukty.com/gohandlers/internal/sorted"
 	"go.ufukty.com/gohandlers/pkg/inspects"
@@ -11,6 +12,66 @@ import (
 
 type bqParse struct{}
 
+// labels name the sources accepting the required and default options in
+// the errors of missing values
+var labels = map[string]string{
+	"route":  "route parameter",
+	"query":  "query parameter",
+	"header": "header",
+	"form":   "form field",
+}
+
+// absent returns the branch for the param that is not sent. Required
+// params return [gohandlers.ErrMissing], and params with defaults pass
+// the default value to the method, in the form arg returns. It is nil for
+// other params. recv is the binding.
+func absent(recv string, bti *inspects.BindingTypeInfo, src, param, fn, method string, arg func(def string) ast.Expr) ast.Stmt {
+	if bti.Required[fn] {
+		return &ast.BlockStmt{List: []ast.Stmt{&ast.ReturnStmt{Results: []ast.Expr{&ast.CallExpr{
+			Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "fmt"}, Sel: &ast.Ident{Name: "Errorf"}},
+			Args: []ast.Expr{
+				&ast.BasicLit{Kind: token.STRING, Value: quotes(fmt.Sprintf("%s.%s: %s %q: %%w", bti.Typename, fn, labels[src], param))},
+				&ast.SelectorExpr{X: &ast.Ident{Name: "gohandlers"}, Sel: &ast.Ident{Name: "ErrMissing"}},
+			},
+		}}}}}
+	}
+	if def, ok := bti.Defaults[fn]; ok {
+		return &ast.BlockStmt{List: []ast.Stmt{&ast.IfStmt{
+			Init: &ast.AssignStmt{
+				Lhs: []ast.Expr{&ast.Ident{Name: "err"}},
+				Tok: token.DEFINE,
+				Rhs: []ast.Expr{&ast.CallExpr{
+					Fun:  &ast.SelectorExpr{X: field(recv, fn), Sel: &ast.Ident{Name: method}},
+					Args: []ast.Expr{arg(def)},
+				}},
+			},
+			Cond: &ast.BinaryExpr{X: &ast.Ident{Name: "err"}, Op: token.NEQ, Y: &ast.Ident{Name: "nil"}},
+			Body: &ast.BlockStmt{List: []ast.Stmt{&ast.ReturnStmt{Results: []ast.Expr{&ast.CallExpr{
+				Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "fmt"}, Sel: &ast.Ident{Name: "Errorf"}},
+				Args: []ast.Expr{
+					&ast.BasicLit{Kind: token.STRING, Value: quotes(fmt.Sprintf("%s.%s.%s: default: %%w", bti.Typename, fn, method))},
+					&ast.Ident{Name: "err"},
+				},
+			}}}}},
+		}}}
+	}
+	return nil
+}
+
+// literal returns the default value as a string
+func literal(def string) ast.Expr {
+	return &ast.BasicLit{Kind: token.STRING, Value: quotes(def)}
+}
+
+// literals returns the default values as a slice of strings
+func literals(defs ...string) ast.Expr {
+	elts := []ast.Expr{}
+	for _, def := range defs {
+		elts = append(elts, literal(def))
+	}
+	return &ast.CompositeLit{Type: &ast.ArrayType{Elt: &ast.Ident{Name: "string"}}, Elts: elts}
+}
+
 func (p *bqParse) contentTypeCheck(info inspects.Info) []ast.Stmt {
 	stmts := []ast.Stmt{}
 	if info.RequestType.ContentType != "" {
@@ -55,6 +116,25 @@ func (p *bqParse) contentTypeCheck(info inspects.Info) []ast.Stmt {
 	return stmts
 }
 
+// queryArg returns the method of the query field and its argument for the
+// array encoding of the param
+func queryArg(enc, qp string) (string, ast.Expr) {
+	get := &ast.CallExpr{
+		Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "q"}, Sel: &ast.Ident{Name: "Get"}},
+		Args: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: quotes(qp)}},
+	}
+	switch enc {
+	case "repeat":
+		return "FromQueryValues", &ast.IndexExpr{X: &ast.Ident{Name: "q"}, Index: &ast.BasicLit{Kind: token.STRING, Value: quotes(qp)}}
+	case "comma":
+		return "FromQueryValues", &ast.CallExpr{
+			Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "strings"}, Sel: &ast.Ident{Name: "Split"}},
+			Args: []ast.Expr{get, &ast.BasicLit{Kind: token.STRING, Value: quotes(",")}},
+		}
+	}
+	return "FromQuery", get
+}
+
 func (p *bqParse) query(info inspects.Info) []ast.Stmt {
 	stmts := []ast.Stmt{}
 	if len(info.RequestType.Params.Query) > 0 {
@@ -70,12 +150,39 @@ func (p *bqParse) query(info inspects.Info) []ast.Stmt {
 		)
 
 		for qp, fn := range sorted.ByValues(info.RequestType.Params.Query) {
+			method, arg := queryArg(info.RequestType.Arrays[qp], qp)
+			var init ast.Stmt
+			var cond ast.Expr = &ast.CallExpr{
+				Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "q"}, Sel: &ast.Ident{Name: "Has"}},
+				Args: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: quotes(qp)}},
+			}
+			if info.RequestType.Objects[qp] {
+				method, arg = "FromQueryObject", &ast.Ident{Name: "object"}
+				init = &ast.AssignStmt{
+					Lhs: []ast.Expr{&ast.Ident{Name: "object"}},
+					Tok: token.DEFINE,
+					Rhs: []ast.Expr{&ast.CallExpr{
+						Fun:  &ast.Ident{Name: "deepObject"},
+						Args: []ast.Expr{&ast.Ident{Name: "q"}, &ast.BasicLit{Kind: token.STRING, Value: quotes(qp)}},
+					}},
+				}
+				cond = &ast.BinaryExpr{
+					X:  &ast.CallExpr{Fun: &ast.Ident{Name: "len"}, Args: []ast.Expr{&ast.Ident{Name: "object"}}},
+					Op: token.GTR,
+					Y:  &ast.BasicLit{Kind: token.INT, Value: "0"},
+				}
+			}
+			def := literal
+			switch info.RequestType.Arrays[qp] {
+			case "repeat":
+				def = func(def string) ast.Expr { return literals(def) }
+			case "comma":
+				def = func(def string) ast.Expr { return literals(strings.Split(def, ",")...) }
+			}
 			stmts = append(stmts,
 				&ast.IfStmt{
-					Cond: &ast.CallExpr{
-						Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "q"}, Sel: &ast.Ident{Name: "Has"}},
-						Args: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: quotes(qp)}},
-					},
+					Init: init,
+					Cond: cond,
 					Body: &ast.BlockStmt{List: []ast.Stmt{
 						&ast.AssignStmt{
 							Lhs: []ast.Expr{&ast.Ident{Name: "err"}},
@@ -83,12 +190,9 @@ func (p *bqParse) query(info inspects.Info) []ast.Stmt {
 							Rhs: []ast.Expr{
 								&ast.CallExpr{
 									Fun: &ast.SelectorExpr{
-										X: &ast.SelectorExpr{X: &ast.Ident{Name: "bq"}, Sel: &ast.Ident{Name: fn}}, Sel: &ast.Ident{Name: "FromQuery"},
+										X: field("bq", fn), Sel: &ast.Ident{Name: method},
 									},
-									Args: []ast.Expr{&ast.CallExpr{
-										Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "q"}, Sel: &ast.Ident{Name: "Get"}},
-										Args: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: quotes(qp)}},
-									}},
+									Args: []ast.Expr{arg},
 								},
 							},
 						},
@@ -97,12 +201,13 @@ func (p *bqParse) query(info inspects.Info) []ast.Stmt {
 							Body: &ast.BlockStmt{List: []ast.Stmt{&ast.ReturnStmt{Results: []ast.Expr{&ast.CallExpr{
 								Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "fmt"}, Sel: &ast.Ident{Name: "Errorf"}},
 								Args: []ast.Expr{
-									&ast.BasicLit{Kind: token.STRING, Value: quotes(fmt.Sprintf("%s.%s.FromQuery: %%w", info.RequestType.Typename, fn))},
+									&ast.BasicLit{Kind: token.STRING, Value: quotes(fmt.Sprintf("%s.%s.%s: %%w", info.RequestType.Typename, fn, method))},
 									&ast.Ident{Name: "err"},
 								},
 							}}}}},
 						},
 					}},
+					Else: absent("bq", info.RequestType, "query", qp, fn, method, def),
 				},
 			)
 		}
@@ -110,74 +215,91 @@ func (p *bqParse) query(info inspects.Info) []ast.Stmt {
 	return stmts
 }
 
-func (p *bqParse) route(info inspects.Info) []ast.Stmt {
+// parseHeaders passes all values of the header, as fields can be
+// multi-valued. msg is the request or response, recv is the binding.
+func parseHeaders(msg, recv string, bti *inspects.BindingTypeInfo) []ast.Stmt {
 	stmts := []ast.Stmt{}
-	for rp, fn := range sorted.ByValues(info.RequestType.Params.Route) {
+	for hp, fn := range sorted.ByValues(bti.Params.Header) {
 		stmts = append(stmts,
 			&ast.IfStmt{
 				Init: &ast.AssignStmt{
-					Lhs: []ast.Expr{&ast.Ident{Name: "err"}},
+					Lhs: []ast.Expr{&ast.Ident{Name: "values"}},
 					Tok: token.DEFINE,
-					Rhs: []ast.Expr{
-						&ast.CallExpr{
-							Fun: &ast.SelectorExpr{
-								X:   &ast.SelectorExpr{X: &ast.Ident{Name: "bq"}, Sel: &ast.Ident{Name: fn}},
-								Sel: &ast.Ident{Name: "FromRoute"},
-							},
-							Args: []ast.Expr{&ast.CallExpr{
-								Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "rq"}, Sel: &ast.Ident{Name: "PathValue"}},
-								Args: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: quotes(rp)}},
-							}},
+					Rhs: []ast.Expr{&ast.CallExpr{
+						Fun: &ast.SelectorExpr{
+							X:   &ast.SelectorExpr{X: &ast.Ident{Name: msg}, Sel: &ast.Ident{Name: "Header"}},
+							Sel: &ast.Ident{Name: "Values"},
 						},
-					},
+						Args: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: quotes(hp)}},
+					}},
+				},
+				Cond: &ast.BinaryExpr{
+					X:  &ast.CallExpr{Fun: &ast.Ident{Name: "len"}, Args: []ast.Expr{&ast.Ident{Name: "values"}}},
+					Op: token.GTR,
+					Y:  &ast.BasicLit{Kind: token.INT, Value: "0"},
 				},
-				Cond: &ast.BinaryExpr{X: &ast.Ident{Name: "err"}, Op: token.NEQ, Y: &ast.Ident{Name: "nil"}},
 				Body: &ast.BlockStmt{List: []ast.Stmt{
-					&ast.ReturnStmt{Results: []ast.Expr{&ast.CallExpr{
-						Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "fmt"}, Sel: &ast.Ident{Name: "Errorf"}},
-						Args: []ast.Expr{
-							&ast.BasicLit{Kind: token.STRING, Value: quotes(fmt.Sprintf("%s.%s.FromRoute: %%w", info.RequestType.Typename, fn))},
-							&ast.Ident{Name: "err"}},
-					}}},
+					&ast.IfStmt{
+						Init: &ast.AssignStmt{
+							Lhs: []ast.Expr{&ast.Ident{Name: "err"}},
+							Tok: token.DEFINE,
+							Rhs: []ast.Expr{&ast.CallExpr{
+								Fun:  &ast.SelectorExpr{X: field(recv, fn), Sel: &ast.Ident{Name: "FromHeader"}},
+								Args: []ast.Expr{&ast.Ident{Name: "values"}},
+							}},
+						},
+						Cond: &ast.BinaryExpr{X: &ast.Ident{Name: "err"}, Op: token.NEQ, Y: &ast.Ident{Name: "nil"}},
+						Body: &ast.BlockStmt{List: []ast.Stmt{&ast.ReturnStmt{Results: []ast.Expr{&ast.CallExpr{
+							Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "fmt"}, Sel: &ast.Ident{Name: "Errorf"}},
+							Args: []ast.Expr{
+								&ast.BasicLit{Kind: token.STRING, Value: quotes(fmt.Sprintf("%s.%s.FromHeader: %%w", bti.Typename, fn))},
+								&ast.Ident{Name: "err"},
+							},
+						}}}}},
+					},
 				}},
+				Else: absent(recv, bti, "header", hp, fn, "FromHeader", func(def string) ast.Expr { return literals(def) }),
 			},
 		)
 	}
 	return stmts
 }
 
-func (p *bqParse) json(info inspects.Info) []ast.Stmt {
+// cookie skips the fields of absent cookies, as the only error
+// [http.Request.Cookie] returns is [http.ErrNoCookie]
+func (p *bqParse) cookie(info inspects.Info) []ast.Stmt {
 	stmts := []ast.Stmt{}
-	if len(info.RequestType.Params.Json) > 0 {
+	for cp, fn := range sorted.ByValues(info.RequestType.Params.Cookie) {
 		stmts = append(stmts,
 			&ast.IfStmt{
 				Init: &ast.AssignStmt{
-					Lhs: []ast.Expr{&ast.Ident{Name: "err"}},
+					Lhs: []ast.Expr{&ast.Ident{Name: "c"}, &ast.Ident{Name: "err"}},
 					Tok: token.DEFINE,
-					Rhs: []ast.Expr{
-						&ast.CallExpr{
-							Fun: &ast.SelectorExpr{
-								X: &ast.CallExpr{
-									Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "json"}, Sel: &ast.Ident{Name: "NewDecoder"}},
-									Args: []ast.Expr{&ast.SelectorExpr{X: &ast.Ident{Name: "rq"}, Sel: &ast.Ident{Name: "Body"}}},
-								},
-								Sel: &ast.Ident{Name: "Decode"},
-							},
-							Args: []ast.Expr{&ast.Ident{Name: "bq"}},
-						},
-					},
+					Rhs: []ast.Expr{&ast.CallExpr{
+						Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "rq"}, Sel: &ast.Ident{Name: "Cookie"}},
+						Args: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: quotes(cp)}},
+					}},
 				},
-				Cond: &ast.BinaryExpr{X: &ast.Ident{Name: "err"}, Op: token.NEQ, Y: &ast.Ident{Name: "nil"}},
+				Cond: &ast.BinaryExpr{X: &ast.Ident{Name: "err"}, Op: token.EQL, Y: &ast.Ident{Name: "nil"}},
 				Body: &ast.BlockStmt{List: []ast.Stmt{
-					&ast.ReturnStmt{Results: []ast.Expr{
-						&ast.CallExpr{
+					&ast.IfStmt{
+						Init: &ast.AssignStmt{
+							Lhs: []ast.Expr{&ast.Ident{Name: "err"}},
+							Tok: token.DEFINE,
+							Rhs: []ast.Expr{&ast.CallExpr{
+								Fun:  &ast.SelectorExpr{X: field("bq", fn), Sel: &ast.Ident{Name: "FromCookie"}},
+								Args: []ast.Expr{&ast.SelectorExpr{X: &ast.Ident{Name: "c"}, Sel: &ast.Ident{Name: "Value"}}},
+							}},
+						},
+						Cond: &ast.BinaryExpr{X: &ast.Ident{Name: "err"}, Op: token.NEQ, Y: &ast.Ident{Name: "nil"}},
+						Body: &ast.BlockStmt{List: []ast.Stmt{&ast.ReturnStmt{Results: []ast.Expr{&ast.CallExpr{
 							Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "fmt"}, Sel: &ast.Ident{Name: "Errorf"}},
 							Args: []ast.Expr{
-								&ast.BasicLit{Kind: token.STRING, Value: `"decoding body: %w"`},
+								&ast.BasicLit{Kind: token.STRING, Value: quotes(fmt.Sprintf("%s.%s.FromCookie: %%w", info.RequestType.Typename, fn))},
 								&ast.Ident{Name: "err"},
 							},
-						},
-					}},
+						}}}}},
+					},
 				}},
 			},
 		)
@@ -185,9 +307,112 @@ func (p *bqParse) json(info inspects.Info) []ast.Stmt {
 	return stmts
 }
 
+func (p *bqParse) route(info inspects.Info) []ast.Stmt {
+	stmts := []ast.Stmt{}
+	for rp, fn := range sorted.ByValues(info.RequestType.Params.Route) {
+		var stmt ast.Stmt = &ast.IfStmt{
+			Init: &ast.AssignStmt{
+				Lhs: []ast.Expr{&ast.Ident{Name: "err"}},
+				Tok: token.DEFINE,
+				Rhs: []ast.Expr{
+					&ast.CallExpr{
+						Fun: &ast.SelectorExpr{
+							X:   field("bq", fn),
+							Sel: &ast.Ident{Name: "FromRoute"},
+						},
+						Args: []ast.Expr{&ast.CallExpr{
+							Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "rq"}, Sel: &ast.Ident{Name: "PathValue"}},
+							Args: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: quotes(rp)}},
+						}},
+					},
+				},
+			},
+			Cond: &ast.BinaryExpr{X: &ast.Ident{Name: "err"}, Op: token.NEQ, Y: &ast.Ident{Name: "nil"}},
+			Body: &ast.BlockStmt{List: []ast.Stmt{
+				&ast.ReturnStmt{Results: []ast.Expr{&ast.CallExpr{
+					Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "fmt"}, Sel: &ast.Ident{Name: "Errorf"}},
+					Args: []ast.Expr{
+						&ast.BasicLit{Kind: token.STRING, Value: quotes(fmt.Sprintf("%s.%s.FromRoute: %%w", info.RequestType.Typename, fn))},
+						&ast.Ident{Name: "err"}},
+				}}},
+			}},
+		}
+		if missing := absent("bq", info.RequestType, "route", rp, fn, "FromRoute", literal); missing != nil {
+			stmt = &ast.IfStmt{
+				Cond: &ast.BinaryExpr{
+					X: &ast.CallExpr{
+						Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "rq"}, Sel: &ast.Ident{Name: "PathValue"}},
+						Args: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: quotes(rp)}},
+					},
+					Op: token.NEQ,
+					Y:  &ast.BasicLit{Kind: token.STRING, Value: quotes("")},
+				},
+				Body: &ast.BlockStmt{List: []ast.Stmt{stmt}},
+				Else: missing,
+			}
+		}
+		stmts = append(stmts, stmt)
+	}
+	return stmts
+}
+
+// json decodes the body into the binding type, or into its view which is
+// copied back when the binding type has fields bound to other sources
+func (p *bqParse) json(info inspects.Info) []ast.Stmt {
+	stmts := []ast.Stmt{}
+	if len(info.RequestType.Params.Json) == 0 {
+		return stmts
+	}
+	var dst ast.Expr = &ast.Ident{Name: "bq"}
+	if info.RequestType.Viewed() {
+		stmts = append(stmts, &ast.AssignStmt{
+			Lhs: []ast.Expr{&ast.Ident{Name: "body"}},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{view(info.RequestType, "bq")},
+		})
+		dst = &ast.UnaryExpr{Op: token.AND, X: &ast.Ident{Name: "body"}}
+	}
+	stmts = append(stmts,
+		&ast.IfStmt{
+			Init: &ast.AssignStmt{
+				Lhs: []ast.Expr{&ast.Ident{Name: "err"}},
+				Tok: token.DEFINE,
+				Rhs: []ast.Expr{
+					&ast.CallExpr{
+						Fun: &ast.SelectorExpr{
+							X: &ast.CallExpr{
+								Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "json"}, Sel: &ast.Ident{Name: "NewDecoder"}},
+								Args: []ast.Expr{&ast.SelectorExpr{X: &ast.Ident{Name: "rq"}, Sel: &ast.Ident{Name: "Body"}}},
+							},
+							Sel: &ast.Ident{Name: "Decode"},
+						},
+						Args: []ast.Expr{dst},
+					},
+				},
+			},
+			Cond: &ast.BinaryExpr{X: &ast.Ident{Name: "err"}, Op: token.NEQ, Y: &ast.Ident{Name: "nil"}},
+			Body: &ast.BlockStmt{List: []ast.Stmt{
+				&ast.ReturnStmt{Results: []ast.Expr{
+					&ast.CallExpr{
+						Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "fmt"}, Sel: &ast.Ident{Name: "Errorf"}},
+						Args: []ast.Expr{
+							&ast.BasicLit{Kind: token.STRING, Value: `"decoding body: %w"`},
+							&ast.Ident{Name: "err"},
+						},
+					},
+				}},
+			}},
+		},
+	)
+	if info.RequestType.Viewed() {
+		stmts = append(stmts, unview(info.RequestType, "bq", "body")...)
+	}
+	return stmts
+}
+
 func (p *bqParse) form(info inspects.Info) []ast.Stmt {
 	stmts := []ast.Stmt{}
-	if len(info.RequestType.Params.Form) > 0 {
+	if len(info.RequestType.Params.Form) > 0 || multipart(info.RequestType) {
 		stmts = append(stmts,
 			&ast.IfStmt{
 				Init: &ast.AssignStmt{
@@ -209,6 +434,19 @@ func (p *bqParse) form(info inspects.Info) []ast.Stmt {
 	return stmts
 }
 
+// body hands over the raw body without reading it
+func (p *bqParse) body(info inspects.Info) []ast.Stmt {
+	stmts := []ast.Stmt{}
+	if info.RequestType.Body != "" {
+		stmts = append(stmts, &ast.AssignStmt{
+			Lhs: []ast.Expr{field("bq", info.RequestType.Body)},
+			Tok: token.ASSIGN,
+			Rhs: []ast.Expr{&ast.SelectorExpr{X: &ast.Ident{Name: "rq"}, Sel: &ast.Ident{Name: "Body"}}},
+		})
+	}
+	return stmts
+}
+
 func (p *bqParse) Produce(info inspects.Info) *ast.FuncDecl {
 	fd := &ast.FuncDecl{
 		Recv: &ast.FieldList{List: []*ast.Field{
@@ -230,8 +468,11 @@ func (p *bqParse) Produce(info inspects.Info) *ast.FuncDecl {
 	fd.Body.List = append(fd.Body.List, p.contentTypeCheck(info)...)
 	fd.Body.List = append(fd.Body.List, p.route(info)...)
 	fd.Body.List = append(fd.Body.List, p.query(info)...)
+	fd.Body.List = append(fd.Body.List, parseHeaders("rq", "bq", info.RequestType)...)
+	fd.Body.List = append(fd.Body.List, p.cookie(info)...)
 	fd.Body.List = append(fd.Body.List, p.json(info)...)
 	fd.Body.List = append(fd.Body.List, p.form(info)...)
+	fd.Body.List = append(fd.Body.List, p.body(info)...)
 
 	fd.Body.List = append(fd.Body.List,
 		&ast.ReturnStmt{Results: []ast.Expr{&ast.Ident{Name: "nil"}}},
@@ -4,11 +4,29 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
+	"strconv"
 
 	"go.ufukty.com/gohandlers/internal/sorted"
 	"go.ufukty.com/gohandlers/pkg/inspects"
 )
 
+// multipart reports if the request body is multipart/form-data
+func multipart(bti *inspects.BindingTypeInfo) bool {
+	return len(bti.Params.File) > 0
+}
+
+// size is the byte size in the shifted form when it is in MiBs
+func size(n int64) ast.Expr {
+	if n > 0 && n%(1<<20) == 0 {
+		return &ast.BinaryExpr{
+			X:  &ast.BasicLit{Kind: token.INT, Value: strconv.FormatInt(n>>20, 10)},
+			Op: token.SHL,
+			Y:  &ast.BasicLit{Kind: token.INT, Value: "20"},
+		}
+	}
+	return &ast.BasicLit{Kind: token.INT, Value: strconv.FormatInt(n, 10)}
+}
+
 func BqUnmarshalFormData(i inspects.Info) *ast.FuncDecl {
 	fd := &ast.FuncDecl{
 		Recv: &ast.FieldList{List: []*ast.Field{
@@ -22,56 +40,123 @@ func BqUnmarshalFormData(i inspects.Info) *ast.FuncDecl {
 			}}},
 			Results: &ast.FieldList{List: []*ast.Field{{Type: &ast.Ident{Name: "error"}}}},
 		},
-		Body: &ast.BlockStmt{List: []ast.Stmt{
-			&ast.IfStmt{
-				Init: &ast.AssignStmt{
-					Lhs: []ast.Expr{&ast.Ident{Name: "err"}},
-					Tok: token.DEFINE,
-					Rhs: []ast.Expr{&ast.CallExpr{Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "rq"}, Sel: &ast.Ident{Name: "ParseForm"}}}},
+		Body: &ast.BlockStmt{List: []ast.Stmt{}},
+	}
+
+	parse := &ast.CallExpr{Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "rq"}, Sel: &ast.Ident{Name: "ParseForm"}}}
+	if multipart(i.RequestType) {
+		parse = &ast.CallExpr{
+			Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "rq"}, Sel: &ast.Ident{Name: "ParseMultipartForm"}},
+			Args: []ast.Expr{size(i.RequestType.MaxMemory)},
+		}
+	}
+	fd.Body.List = append(fd.Body.List,
+		&ast.IfStmt{
+			Init: &ast.AssignStmt{
+				Lhs: []ast.Expr{&ast.Ident{Name: "err"}},
+				Tok: token.DEFINE,
+				Rhs: []ast.Expr{parse},
+			},
+			Cond: &ast.BinaryExpr{X: &ast.Ident{Name: "err"}, Op: token.NEQ, Y: &ast.Ident{Name: "nil"}},
+			Body: &ast.BlockStmt{List: []ast.Stmt{&ast.ReturnStmt{Results: []ast.Expr{&ast.CallExpr{
+				Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "fmt"}, Sel: &ast.Ident{Name: "Errorf"}},
+				Args: []ast.Expr{
+					&ast.BasicLit{Kind: token.STRING, Value: quotes(fmt.Sprintf("%s: %%w", parse.Fun.(*ast.SelectorExpr).Sel.Name))},
+					&ast.Ident{Name: "err"},
+				},
+			}}}}},
+		},
+	)
+
+	for p, fn := range sorted.ByValues(i.RequestType.Params.Form) {
+		stmt := &ast.IfStmt{
+			Init: &ast.AssignStmt{
+				Lhs: []ast.Expr{&ast.Ident{Name: "err"}},
+				Tok: token.DEFINE,
+				Rhs: []ast.Expr{
+					&ast.CallExpr{
+						Fun: &ast.SelectorExpr{
+							X:   field("bq", fn),
+							Sel: &ast.Ident{Name: "FromForm"},
+						},
+						Args: []ast.Expr{
+							&ast.CallExpr{
+								Fun: &ast.Ident{Name: "firstOrZero"},
+								Args: []ast.Expr{
+									&ast.IndexExpr{
+										X:     &ast.SelectorExpr{X: &ast.Ident{Name: "rq"}, Sel: &ast.Ident{Name: "PostForm"}},
+										Index: &ast.BasicLit{Kind: token.STRING, Value: quotes(p)},
+									},
+								},
+							},
+						},
+					},
 				},
-				Cond: &ast.BinaryExpr{X: &ast.Ident{Name: "err"}, Op: token.NEQ, Y: &ast.Ident{Name: "nil"}},
-				Body: &ast.BlockStmt{List: []ast.Stmt{&ast.ReturnStmt{Results: []ast.Expr{&ast.CallExpr{
-					Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "fmt"}, Sel: &ast.Ident{Name: "Errorf"}},
-					Args: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: `"ParseForm: %w"`}, &ast.Ident{Name: "err"}},
-				}}}}},
 			},
-		}},
+			Cond: &ast.BinaryExpr{X: &ast.Ident{Name: "err"}, Op: token.NEQ, Y: &ast.Ident{Name: "nil"}},
+			Body: &ast.BlockStmt{List: []ast.Stmt{&ast.ReturnStmt{Results: []ast.Expr{&ast.CallExpr{
+				Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "fmt"}, Sel: &ast.Ident{Name: "Errorf"}},
+				Args: []ast.Expr{
+					&ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf(`"%s: FromForm: %%w"`, fn)},
+					&ast.Ident{Name: "err"},
+				},
+			}}}}},
+		}
+		has := &ast.IfStmt{
+			Cond: &ast.CallExpr{
+				Fun: &ast.SelectorExpr{
+					X:   &ast.SelectorExpr{X: &ast.Ident{Name: "rq"}, Sel: &ast.Ident{Name: "PostForm"}},
+					Sel: &ast.Ident{Name: "Has"},
+				},
+				Args: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: quotes(p)}},
+			},
+			Body: &ast.BlockStmt{List: []ast.Stmt{stmt}},
+		}
+		if missing := absent("bq", i.RequestType, "form", p, fn, "FromForm", literal); missing != nil {
+			has.Else = missing
+		}
+		fd.Body.List = append(fd.Body.List, has)
 	}
 
-	for p, fn := range sorted.ByValues(i.RequestType.Params.Form) {
+	for p, fn := range sorted.ByValues(i.RequestType.Params.File) {
 		fd.Body.List = append(fd.Body.List,
 			&ast.IfStmt{
 				Init: &ast.AssignStmt{
-					Lhs: []ast.Expr{&ast.Ident{Name: "err"}},
+					Lhs: []ast.Expr{&ast.Ident{Name: "fhs"}},
 					Tok: token.DEFINE,
-					Rhs: []ast.Expr{
-						&ast.CallExpr{
-							Fun: &ast.SelectorExpr{
-								X:   &ast.SelectorExpr{X: &ast.Ident{Name: "bq"}, Sel: &ast.Ident{Name: fn}},
-								Sel: &ast.Ident{Name: "FromForm"},
-							},
-							Args: []ast.Expr{
-								&ast.CallExpr{
-									Fun: &ast.Ident{Name: "firstOrZero"},
-									Args: []ast.Expr{
-										&ast.IndexExpr{
-											X:     &ast.SelectorExpr{X: &ast.Ident{Name: "rq"}, Sel: &ast.Ident{Name: "PostForm"}},
-											Index: &ast.BasicLit{Kind: token.STRING, Value: quotes(p)},
-										},
-									},
-								},
-							},
+					Rhs: []ast.Expr{&ast.IndexExpr{
+						X: &ast.SelectorExpr{
+							X:   &ast.SelectorExpr{X: &ast.Ident{Name: "rq"}, Sel: &ast.Ident{Name: "MultipartForm"}},
+							Sel: &ast.Ident{Name: "File"},
 						},
-					},
+						Index: &ast.BasicLit{Kind: token.STRING, Value: quotes(p)},
+					}},
 				},
-				Cond: &ast.BinaryExpr{X: &ast.Ident{Name: "err"}, Op: token.NEQ, Y: &ast.Ident{Name: "nil"}},
-				Body: &ast.BlockStmt{List: []ast.Stmt{&ast.ReturnStmt{Results: []ast.Expr{&ast.CallExpr{
-					Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "fmt"}, Sel: &ast.Ident{Name: "Errorf"}},
-					Args: []ast.Expr{
-						&ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf(`"%s: FromForm: %%w"`, fn)},
-						&ast.Ident{Name: "err"},
+				Cond: &ast.BinaryExpr{
+					X:  &ast.CallExpr{Fun: &ast.Ident{Name: "len"}, Args: []ast.Expr{&ast.Ident{Name: "fhs"}}},
+					Op: token.GTR,
+					Y:  &ast.BasicLit{Kind: token.INT, Value: "0"},
+				},
+				Body: &ast.BlockStmt{List: []ast.Stmt{
+					&ast.IfStmt{
+						Init: &ast.AssignStmt{
+							Lhs: []ast.Expr{&ast.Ident{Name: "err"}},
+							Tok: token.DEFINE,
+							Rhs: []ast.Expr{&ast.CallExpr{
+								Fun:  &ast.SelectorExpr{X: field("bq", fn), Sel: &ast.Ident{Name: "FromFile"}},
+								Args: []ast.Expr{&ast.Ident{Name: "fhs"}},
+							}},
+						},
+						Cond: &ast.BinaryExpr{X: &ast.Ident{Name: "err"}, Op: token.NEQ, Y: &ast.Ident{Name: "nil"}},
+						Body: &ast.BlockStmt{List: []ast.Stmt{&ast.ReturnStmt{Results: []ast.Expr{&ast.CallExpr{
+							Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "fmt"}, Sel: &ast.Ident{Name: "Errorf"}},
+							Args: []ast.Expr{
+								&ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf(`"%s: FromFile: %%w"`, fn)},
+								&ast.Ident{Name: "err"},
+							},
+						}}}}},
 					},
-				}}}}},
+				}},
 			},
 		)
 	}
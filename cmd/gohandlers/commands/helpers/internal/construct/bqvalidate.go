@@ -40,8 +40,11 @@ func BqValidate(bti *inspects.BindingTypeInfo) *ast.FuncDecl {
 	}
 	params := merge(
 		bti.Params.Form,
+		bti.Params.File,
 		bti.Params.Json,
 		bti.Params.Query,
+		bti.Params.Header,
+		bti.Params.Cookie,
 		bti.Params.Route,
 	)
 	for p, fn := range sorted.ByValues(params) {
@@ -50,7 +53,7 @@ func BqValidate(bti *inspects.BindingTypeInfo) *ast.FuncDecl {
 				Lhs: []ast.Expr{&ast.Ident{Name: "issue"}},
 				Tok: token.DEFINE,
 				Rhs: []ast.Expr{&ast.CallExpr{Fun: &ast.SelectorExpr{
-					X:   &ast.SelectorExpr{X: &ast.Ident{Name: "bq"}, Sel: &ast.Ident{Name: fn}},
+					X:   field("bq", fn),
 					Sel: &ast.Ident{Name: "Validate"},
 				}}},
 			},
@@ -1,9 +1,11 @@
 package construct
 
 import (
+	"fmt"
 	"go/ast"
 	"go/token"
 
+	"go.ufukty.com/gohandlers/internal/sorted"
 	"go.ufukty.com/gohandlers/pkg/inspects"
 )
 
@@ -66,38 +68,111 @@ func (p *bsParse) contentTypeCheck(info inspects.Info) []ast.Stmt {
 	}
 }
 
+func (p *bsParse) status(info inspects.Info) []ast.Stmt {
+	stmts := []ast.Stmt{}
+	if info.ResponseType.Status != "" {
+		stmts = append(stmts, &ast.AssignStmt{
+			Lhs: []ast.Expr{field("bs", info.ResponseType.Status)},
+			Tok: token.ASSIGN,
+			Rhs: []ast.Expr{&ast.SelectorExpr{X: &ast.Ident{Name: "rs"}, Sel: &ast.Ident{Name: "StatusCode"}}},
+		})
+	}
+	return stmts
+}
+
+// cookies reads the cookies set by the response
+func (p *bsParse) cookies(info inspects.Info) []ast.Stmt {
+	stmts := []ast.Stmt{}
+	if len(info.ResponseType.Params.Cookie) > 0 {
+		clauses := []ast.Stmt{}
+		for cp, fn := range sorted.ByValues(info.ResponseType.Params.Cookie) {
+			clauses = append(clauses, &ast.CaseClause{
+				List: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: quotes(cp)}},
+				Body: []ast.Stmt{
+					&ast.IfStmt{
+						Init: &ast.AssignStmt{
+							Lhs: []ast.Expr{&ast.Ident{Name: "err"}},
+							Tok: token.DEFINE,
+							Rhs: []ast.Expr{&ast.CallExpr{
+								Fun:  &ast.SelectorExpr{X: field("bs", fn), Sel: &ast.Ident{Name: "FromCookie"}},
+								Args: []ast.Expr{&ast.SelectorExpr{X: &ast.Ident{Name: "c"}, Sel: &ast.Ident{Name: "Value"}}},
+							}},
+						},
+						Cond: &ast.BinaryExpr{X: &ast.Ident{Name: "err"}, Op: token.NEQ, Y: &ast.Ident{Name: "nil"}},
+						Body: &ast.BlockStmt{List: []ast.Stmt{&ast.ReturnStmt{Results: []ast.Expr{&ast.CallExpr{
+							Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "fmt"}, Sel: &ast.Ident{Name: "Errorf"}},
+							Args: []ast.Expr{
+								&ast.BasicLit{Kind: token.STRING, Value: quotes(fmt.Sprintf("%s.%s.FromCookie: %%w", info.ResponseType.Typename, fn))},
+								&ast.Ident{Name: "err"},
+							},
+						}}}}},
+					},
+				},
+			})
+		}
+		stmts = append(stmts, &ast.RangeStmt{
+			Key:   &ast.Ident{Name: "_"},
+			Value: &ast.Ident{Name: "c"},
+			Tok:   token.DEFINE,
+			X:     &ast.CallExpr{Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "rs"}, Sel: &ast.Ident{Name: "Cookies"}}},
+			Body: &ast.BlockStmt{List: []ast.Stmt{
+				&ast.SwitchStmt{
+					Tag:  &ast.SelectorExpr{X: &ast.Ident{Name: "c"}, Sel: &ast.Ident{Name: "Name"}},
+					Body: &ast.BlockStmt{List: clauses},
+				},
+			}},
+		})
+	}
+	return stmts
+}
+
+// json decodes the body into the binding type, or into its view which is
+// copied back when the binding type has fields bound to other sources
 func (p *bsParse) json(info inspects.Info) []ast.Stmt {
 	stmts := []ast.Stmt{}
-	if len(info.ResponseType.Params.Json) > 0 {
-		stmts = append(stmts,
-			&ast.IfStmt{
-				Init: &ast.AssignStmt{
-					Lhs: []ast.Expr{&ast.Ident{Name: "err"}},
-					Tok: token.DEFINE,
-					Rhs: []ast.Expr{
-						&ast.CallExpr{
-							Fun: &ast.SelectorExpr{
-								X: &ast.CallExpr{
-									Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "json"}, Sel: &ast.Ident{Name: "NewDecoder"}},
-									Args: []ast.Expr{&ast.SelectorExpr{X: &ast.Ident{Name: "rs"}, Sel: &ast.Ident{Name: "Body"}}},
-								},
-								Sel: &ast.Ident{Name: "Decode"},
+	if len(info.ResponseType.Params.Json) == 0 {
+		return stmts
+	}
+	var dst ast.Expr = &ast.Ident{Name: "bs"}
+	if info.ResponseType.Viewed() {
+		stmts = append(stmts, &ast.AssignStmt{
+			Lhs: []ast.Expr{&ast.Ident{Name: "body"}},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{view(info.ResponseType, "bs")},
+		})
+		dst = &ast.UnaryExpr{Op: token.AND, X: &ast.Ident{Name: "body"}}
+	}
+	stmts = append(stmts,
+		&ast.IfStmt{
+			Init: &ast.AssignStmt{
+				Lhs: []ast.Expr{&ast.Ident{Name: "err"}},
+				Tok: token.DEFINE,
+				Rhs: []ast.Expr{
+					&ast.CallExpr{
+						Fun: &ast.SelectorExpr{
+							X: &ast.CallExpr{
+								Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "json"}, Sel: &ast.Ident{Name: "NewDecoder"}},
+								Args: []ast.Expr{&ast.SelectorExpr{X: &ast.Ident{Name: "rs"}, Sel: &ast.Ident{Name: "Body"}}},
 							},
-							Args: []ast.Expr{&ast.Ident{Name: "bs"}},
+							Sel: &ast.Ident{Name: "Decode"},
 						},
+						Args: []ast.Expr{dst},
 					},
 				},
-				Cond: &ast.BinaryExpr{X: &ast.Ident{Name: "err"}, Op: token.NEQ, Y: &ast.Ident{Name: "nil"}},
-				Body: &ast.BlockStmt{List: []ast.Stmt{
-					&ast.ReturnStmt{Results: []ast.Expr{
-						&ast.CallExpr{
-							Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "fmt"}, Sel: &ast.Ident{Name: "Errorf"}},
-							Args: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: `"decoding the body: %w"`}, &ast.Ident{Name: "err"}},
-						},
-					}},
-				}},
 			},
-		)
+			Cond: &ast.BinaryExpr{X: &ast.Ident{Name: "err"}, Op: token.NEQ, Y: &ast.Ident{Name: "nil"}},
+			Body: &ast.BlockStmt{List: []ast.Stmt{
+				&ast.ReturnStmt{Results: []ast.Expr{
+					&ast.CallExpr{
+						Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "fmt"}, Sel: &ast.Ident{Name: "Errorf"}},
+						Args: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: `"decoding the body: %w"`}, &ast.Ident{Name: "err"}},
+					},
+				}},
+			}},
+		},
+	)
+	if info.ResponseType.Viewed() {
+		stmts = append(stmts, unview(info.ResponseType, "bs", "body")...)
 	}
 	return stmts
 }
@@ -119,6 +194,9 @@ func (p *bsParse) Produce(info inspects.Info) *ast.FuncDecl {
 	}
 
 	fd.Body.List = append(fd.Body.List, p.contentTypeCheck(info)...)
+	fd.Body.List = append(fd.Body.List, p.status(info)...)
+	fd.Body.List = append(fd.Body.List, parseHeaders("rs", "bs", info.ResponseType)...)
+	fd.Body.List = append(fd.Body.List, p.cookies(info)...)
 	fd.Body.List = append(fd.Body.List, p.json(info)...)
 
 	fd.Body.List = append(fd.Body.List,
@@ -1,13 +1,33 @@
 package construct
 
 import (
+	"fmt"
 	"go/ast"
 	"go/token"
+	"net/http"
+	"strconv"
 
+	"go.ufukty.com/gohandlers/internal/sorted"
 	"go.ufukty.com/gohandlers/pkg/inspects"
 )
 
-type bsWrite struct{}
+type bsWriteSymbolTable struct {
+	encoded bool
+	err     bool
+	values  bool
+}
+
+type bsWrite struct {
+	table bsWriteSymbolTable
+}
+
+// status refers to the success status code, http.StatusOK when unspecified
+func status(code int) ast.Expr {
+	if code == 0 || code == http.StatusOK {
+		return &ast.SelectorExpr{X: &ast.Ident{Name: "http"}, Sel: &ast.Ident{Name: "StatusOK"}}
+	}
+	return &ast.BasicLit{Kind: token.INT, Value: strconv.Itoa(code)}
+}
 
 func (p *bsWrite) headers(info inspects.Info) []ast.Stmt {
 	stmts := []ast.Stmt{}
@@ -27,17 +47,134 @@ func (p *bsWrite) headers(info inspects.Info) []ast.Stmt {
 			},
 		)
 	}
-	stmts = append(stmts,
-		&ast.ExprStmt{
-			X: &ast.CallExpr{
-				Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "w"}, Sel: &ast.Ident{Name: "WriteHeader"}},
-				Args: []ast.Expr{&ast.SelectorExpr{X: &ast.Ident{Name: "http"}, Sel: &ast.Ident{Name: "StatusOK"}}},
+	for hp, fn := range sorted.ByValues(info.ResponseType.Params.Header) {
+		stmts = append(stmts,
+			&ast.AssignStmt{
+				Lhs: []ast.Expr{&ast.Ident{Name: "values"}, &ast.Ident{Name: "err"}},
+				Tok: ternary(p.table.values, token.ASSIGN, token.DEFINE),
+				Rhs: []ast.Expr{&ast.CallExpr{Fun: &ast.SelectorExpr{
+					X:   field("bs", fn),
+					Sel: &ast.Ident{Name: "ToHeader"},
+				}}},
 			},
-		},
-	)
+			&ast.IfStmt{
+				Cond: &ast.BinaryExpr{X: &ast.Ident{Name: "err"}, Op: token.NEQ, Y: &ast.Ident{Name: "nil"}},
+				Body: &ast.BlockStmt{List: []ast.Stmt{
+					&ast.ReturnStmt{Results: []ast.Expr{
+						&ast.CallExpr{
+							Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "fmt"}, Sel: &ast.Ident{Name: "Errorf"}},
+							Args: []ast.Expr{
+								&ast.BasicLit{Kind: token.STRING, Value: quotes(fmt.Sprintf("%s.%s.ToHeader: %%w", info.ResponseType.Typename, fn))},
+								&ast.Ident{Name: "err"},
+							},
+						},
+					}},
+				}},
+			},
+			&ast.RangeStmt{
+				Key:   &ast.Ident{Name: "_"},
+				Value: &ast.Ident{Name: "v"},
+				Tok:   token.DEFINE,
+				X:     &ast.Ident{Name: "values"},
+				Body: &ast.BlockStmt{List: []ast.Stmt{
+					&ast.ExprStmt{X: &ast.CallExpr{
+						Fun: &ast.SelectorExpr{
+							X:   &ast.CallExpr{Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "w"}, Sel: &ast.Ident{Name: "Header"}}},
+							Sel: &ast.Ident{Name: "Add"},
+						},
+						Args: []ast.Expr{
+							&ast.BasicLit{Kind: token.STRING, Value: quotes(hp)},
+							&ast.Ident{Name: "v"},
+						},
+					}},
+				}},
+			},
+		)
+		p.table.values = true
+		p.table.err = true
+	}
 	return stmts
 }
 
+// cookies sets the cookies of fields that have a value. Attributes are
+// left to the defaults of user agents.
+func (p *bsWrite) cookies(info inspects.Info) []ast.Stmt {
+	stmts := []ast.Stmt{}
+	for cp, fn := range sorted.ByValues(info.ResponseType.Params.Cookie) {
+		stmts = append(stmts,
+			&ast.AssignStmt{
+				Lhs: []ast.Expr{&ast.Ident{Name: "encoded"}, &ast.Ident{Name: "ok"}, &ast.Ident{Name: "err"}},
+				Tok: ternary(p.table.encoded && p.table.err, token.ASSIGN, token.DEFINE),
+				Rhs: []ast.Expr{&ast.CallExpr{Fun: &ast.SelectorExpr{
+					X:   field("bs", fn),
+					Sel: &ast.Ident{Name: "ToCookie"},
+				}}},
+			},
+			&ast.IfStmt{
+				Cond: &ast.BinaryExpr{X: &ast.Ident{Name: "err"}, Op: token.NEQ, Y: &ast.Ident{Name: "nil"}},
+				Body: &ast.BlockStmt{List: []ast.Stmt{
+					&ast.ReturnStmt{Results: []ast.Expr{
+						&ast.CallExpr{
+							Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "fmt"}, Sel: &ast.Ident{Name: "Errorf"}},
+							Args: []ast.Expr{
+								&ast.BasicLit{Kind: token.STRING, Value: quotes(fmt.Sprintf("%s.%s.ToCookie: %%w", info.ResponseType.Typename, fn))},
+								&ast.Ident{Name: "err"},
+							},
+						},
+					}},
+				}},
+			},
+			&ast.IfStmt{
+				Cond: &ast.Ident{Name: "ok"},
+				Body: &ast.BlockStmt{List: append(
+					newCookie(info.ResponseType, fn, cp, nil),
+					&ast.ExprStmt{X: &ast.CallExpr{
+						Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "http"}, Sel: &ast.Ident{Name: "SetCookie"}},
+						Args: []ast.Expr{&ast.Ident{Name: "w"}, &ast.Ident{Name: "cookie"}},
+					}},
+				)},
+			},
+		)
+		p.table.encoded = true
+		p.table.err = true
+	}
+	return stmts
+}
+
+// status writes the status field when it is set, and the success status
+// code otherwise
+func (p *bsWrite) status(info inspects.Info) []ast.Stmt {
+	if info.ResponseType.Status == "" {
+		return []ast.Stmt{
+			&ast.ExprStmt{X: &ast.CallExpr{
+				Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "w"}, Sel: &ast.Ident{Name: "WriteHeader"}},
+				Args: []ast.Expr{status(info.Status)},
+			}},
+		}
+	}
+	return []ast.Stmt{
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{&ast.Ident{Name: "status"}},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{status(info.Status)},
+		},
+		&ast.IfStmt{
+			Cond: &ast.BinaryExpr{X: field("bs", info.ResponseType.Status), Op: token.NEQ, Y: &ast.BasicLit{Kind: token.INT, Value: "0"}},
+			Body: &ast.BlockStmt{List: []ast.Stmt{
+				&ast.AssignStmt{
+					Lhs: []ast.Expr{&ast.Ident{Name: "status"}},
+					Tok: token.ASSIGN,
+					Rhs: []ast.Expr{field("bs", info.ResponseType.Status)},
+				},
+			}},
+		},
+		&ast.ExprStmt{X: &ast.CallExpr{
+			Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "w"}, Sel: &ast.Ident{Name: "WriteHeader"}},
+			Args: []ast.Expr{&ast.Ident{Name: "status"}},
+		}},
+	}
+}
+
 func (p *bsWrite) json(info inspects.Info) []ast.Stmt {
 	stmts := []ast.Stmt{}
 	if len(info.ResponseType.Params.Json) > 0 {
@@ -55,7 +192,7 @@ func (p *bsWrite) json(info inspects.Info) []ast.Stmt {
 								},
 								Sel: &ast.Ident{Name: "Encode"},
 							},
-							Args: []ast.Expr{&ast.Ident{Name: "bs"}},
+							Args: []ast.Expr{ternary(info.ResponseType.Viewed(), view(info.ResponseType, "bs"), ast.Expr(&ast.Ident{Name: "bs"}))},
 						},
 					},
 				},
@@ -92,6 +229,8 @@ func (p *bsWrite) Produce(info inspects.Info) *ast.FuncDecl {
 	}
 
 	fd.Body.List = append(fd.Body.List, p.headers(info)...)
+	fd.Body.List = append(fd.Body.List, p.cookies(info)...)
+	fd.Body.List = append(fd.Body.List, p.status(info)...)
 	fd.Body.List = append(fd.Body.List, p.json(info)...)
 
 	fd.Body.List = append(fd.Body.List, &ast.ReturnStmt{
@@ -3,11 +3,47 @@ package construct
 import (
 	"go/ast"
 	"go/token"
+	"maps"
 	"slices"
+	"strings"
 
 	"go.ufukty.com/gohandlers/pkg/inspects"
 )
 
+// receiver is the pointer type unless all handlers use value receivers.
+// Type parameters are named as the first handler does.
+func receiver(recvt inspects.Receiver, infos map[string]inspects.Info) ast.Expr {
+	pointer := false
+	var params []ast.Expr
+	for _, hn := range slices.Sorted(maps.Keys(infos)) {
+		t := infos[hn].Recv
+		if s, ok := t.(*ast.StarExpr); ok {
+			pointer = true
+			t = s.X
+		}
+		if params == nil {
+			switch t := t.(type) {
+			case *ast.IndexExpr:
+				params = []ast.Expr{t.Index}
+			case *ast.IndexListExpr:
+				params = t.Indices
+			}
+		}
+	}
+	var t ast.Expr = &ast.Ident{Name: recvt.Type}
+	switch len(params) {
+	case 0:
+	case 1:
+		t = &ast.IndexExpr{X: t, Index: params[0]}
+	default:
+		t = &ast.IndexListExpr{X: t, Indices: params}
+	}
+	if pointer {
+		t = &ast.StarExpr{X: t}
+	}
+	return t
+}
+
 func Listers(infoss map[inspects.Receiver]map[string]inspects.Info) []ast.Decl {
 	var handlerinfo ast.Expr = &ast.SelectorExpr{
 		X:   ast.NewIdent("gohandlers"),
@@ -15,7 +51,11 @@ func Listers(infoss map[inspects.Receiver]map[string]inspects.Info) []ast.Decl {
 	}
 
 	fds := []ast.Decl{}
-	for recvt, infos := range infoss {
+	recvs := slices.SortedFunc(maps.Keys(infoss), func(a, b inspects.Receiver) int {
+		return strings.Compare(a.Type, b.Type)
+	})
+	for _, recvt := range recvs {
+		infos := infoss[recvt]
 		elts := []ast.Expr{}
 		for hn, info := range infos {
 			kv := &ast.KeyValueExpr{
@@ -41,10 +81,16 @@ func Listers(infoss map[inspects.Receiver]map[string]inspects.Info) []ast.Decl {
 			}
 		})
 
+		// factory arguments are taken in the order of handler names
+		params := []*ast.Field{}
+		for _, hn := range slices.Sorted(maps.Keys(infos)) {
+			params = append(params, infos[hn].Args...)
+		}
+
 		fd := &ast.FuncDecl{
 			Name: &ast.Ident{Name: "ListHandlers"},
 			Type: &ast.FuncType{
-				Params: &ast.FieldList{List: []*ast.Field{}},
+				Params: &ast.FieldList{List: params},
 				Results: &ast.FieldList{List: []*ast.Field{
 					{Type: &ast.MapType{Key: &ast.Ident{Name: "string"}, Value: handlerinfo}},
 				}},
@@ -60,32 +106,12 @@ func Listers(infoss map[inspects.Receiver]map[string]inspects.Info) []ast.Decl {
 		if recvt.Type != "" {
 			fd.Recv = &ast.FieldList{List: []*ast.Field{{
 				Names: []*ast.Ident{{Name: recvt.Name}},
-				Type:  &ast.StarExpr{X: &ast.Ident{Name: recvt.Type}},
+				Type:  receiver(recvt, infos),
 			}}}
 		}
 
 		fds = append(fds, fd)
 	}
 
-	slices.SortFunc(fds, func(a, b ast.Decl) int {
-		if a.(*ast.FuncDecl).Recv == nil {
-			return -1
-		}
-		if b.(*ast.FuncDecl).Recv == nil {
-			return 1
-		}
-
-		at := a.(*ast.FuncDecl).Recv.List[0].Type.(*ast.StarExpr).X.(*ast.Ident).Name
-		bt := b.(*ast.FuncDecl).Recv.List[0].Type.(*ast.StarExpr).X.(*ast.Ident).Name
-
-		if at < bt {
-			return -1
-		} else if at == bt {
-			return 0
-		} else {
-			return 1
-		}
-	})
-
 	return fds
 }
@@ -0,0 +1,65 @@
+package construct
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/types"
+	"testing"
+
+	"go.ufukty.com/gohandlers/pkg/inspects"
+)
+
+func TestReceiver(t *testing.T) {
+	type tc struct {
+		recvt    string
+		recvs    map[string]string // handler -> receiver
+		expected string
+	}
+	tcs := map[string]tc{
+		"values":         {"Pets", map[string]string{"Get": "Pets", "List": "Pets"}, "Pets"},
+		"mixed":          {"Pets", map[string]string{"Get": "Pets", "List": "*Pets"}, "*Pets"},
+		"type param":     {"Store", map[string]string{"Get": "Store[T]"}, "Store[T]"},
+		"type params":    {"Store", map[string]string{"Get": "Store[K, V]", "List": "*Store[K, V]"}, "*Store[K, V]"},
+		"named by first": {"Store", map[string]string{"Get": "*Store[K, V]", "List": "*Store[Key, Value]"}, "*Store[K, V]"},
+	}
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			infos := map[string]inspects.Info{}
+			for hn, recv := range tc.recvs {
+				e, err := parser.ParseExpr(recv)
+				if err != nil {
+					t.Fatalf("prep: %v", err)
+				}
+				infos[hn] = inspects.Info{Recv: e}
+			}
+			got := types.ExprString(receiver(inspects.Receiver{Name: "re", Type: tc.recvt}, infos))
+			if got != tc.expected {
+				t.Errorf("expected %s got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestListers_receiver(t *testing.T) {
+	e, err := parser.ParseExpr("*Store[K, V]")
+	if err != nil {
+		t.Fatalf("prep: %v", err)
+	}
+	infoss := map[inspects.Receiver]map[string]inspects.Info{
+		{Name: "st", Type: "Store"}: {"Get": {Method: "GET", Path: "/get", Recv: e, Ref: ast.NewIdent("st.Get")}},
+	}
+	fds := Listers(infoss)
+	if len(fds) != 1 {
+		t.Fatalf("expected 1 lister got %d", len(fds))
+	}
+	fd := fds[0].(*ast.FuncDecl)
+	if fd.Recv == nil || len(fd.Recv.List) != 1 {
+		t.Fatalf("expected the lister to have a receiver")
+	}
+	if n := fd.Recv.List[0].Names[0].Name; n != "st" {
+		t.Errorf("expected the receiver name st got %s", n)
+	}
+	if got := types.ExprString(fd.Recv.List[0].Type); got != "*Store[K, V]" {
+		t.Errorf("expected *Store[K, V] got %s", got)
+	}
+}
@@ -0,0 +1,16 @@
+package construct
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// field returns the selector expression for the dot separated field path,
+// which contains the embedded struct names for promoted fields
+func field(recv, path string) ast.Expr {
+	var x ast.Expr = &ast.Ident{Name: recv}
+	for _, n := range strings.Split(path, ".") {
+		x = &ast.SelectorExpr{X: x, Sel: &ast.Ident{Name: n}}
+	}
+	return x
+}
@@ -0,0 +1,102 @@
+package construct
+
+import (
+	"cmp"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"go.ufukty.com/gohandlers/pkg/inspects"
+)
+
+// viewName is the name of the struct the json fields are copied into
+func viewName(bti *inspects.BindingTypeInfo) string {
+	r, n := utf8.DecodeRuneInString(bti.Typename)
+	return string(unicode.ToLower(r)) + bti.Typename[n:] + "Body"
+}
+
+type viewField struct {
+	name string // in the view
+	path string // in the binding type
+}
+
+// viewFields lists the json fields in the declaration order. Fields of
+// embedded structs are named after their paths.
+func viewFields(bti *inspects.BindingTypeInfo) []viewField {
+	vfs := []viewField{}
+	for _, fp := range bti.Declared {
+		if _, ok := bti.JsonTypes[fp]; !ok {
+			continue
+		}
+		name := ""
+		for _, s := range strings.Split(fp, ".") {
+			r, n := utf8.DecodeRuneInString(s)
+			name += string(unicode.ToUpper(r)) + s[n:]
+		}
+		vfs = append(vfs, viewField{name, fp})
+	}
+	return vfs
+}
+
+// viewTag returns the json tag of the field in the view, which names the
+// param explicitly as the field is renamed for promoted fields
+func viewTag(bti *inspects.BindingTypeInfo, fp string) string {
+	name, opts, found := strings.Cut(bti.JsonTags[fp], ",")
+	name = cmp.Or(name, fp[strings.LastIndex(fp, ".")+1:])
+	if found {
+		name += "," + opts
+	}
+	return "`json:" + strconv.Quote(name) + "`"
+}
+
+// View declares the struct the json fields of the binding type are encoded
+// and decoded through, with the same types and tags. Binding types without
+// fields bound to other sources are encoded as a whole, and don't need it.
+func View(bti *inspects.BindingTypeInfo) (*ast.GenDecl, bool) {
+	if !bti.Viewed() {
+		return nil, false
+	}
+	fields := []*ast.Field{}
+	for _, vf := range viewFields(bti) {
+		fields = append(fields, &ast.Field{
+			Names: []*ast.Ident{{Name: vf.name}},
+			Type:  bti.JsonTypes[vf.path],
+			Tag:   &ast.BasicLit{Kind: token.STRING, Value: viewTag(bti, vf.path)},
+		})
+	}
+	return &ast.GenDecl{
+		Tok: token.TYPE,
+		Specs: []ast.Spec{&ast.TypeSpec{
+			Name: &ast.Ident{Name: viewName(bti)},
+			Type: &ast.StructType{Fields: &ast.FieldList{List: fields}},
+		}},
+	}, true
+}
+
+// view returns the literal of the view with the json fields of recv
+func view(bti *inspects.BindingTypeInfo, recv string) ast.Expr {
+	elts := []ast.Expr{}
+	for _, vf := range viewFields(bti) {
+		elts = append(elts, &ast.KeyValueExpr{
+			Key:   &ast.Ident{Name: vf.name},
+			Value: field(recv, vf.path),
+		})
+	}
+	return &ast.CompositeLit{Type: &ast.Ident{Name: viewName(bti)}, Elts: elts}
+}
+
+// unview copies the json fields of the view in v back into recv
+func unview(bti *inspects.BindingTypeInfo, recv, v string) []ast.Stmt {
+	stmts := []ast.Stmt{}
+	for _, vf := range viewFields(bti) {
+		stmts = append(stmts, &ast.AssignStmt{
+			Lhs: []ast.Expr{field(recv, vf.path)},
+			Tok: token.ASSIGN,
+			Rhs: []ast.Expr{&ast.SelectorExpr{X: &ast.Ident{Name: v}, Sel: &ast.Ident{Name: vf.name}}},
+		})
+	}
+	return stmts
+}
@@ -14,7 +14,7 @@ import (
 func needsStrings(infoss map[inspects.Receiver]map[string]inspects.Info) bool {
 	for _, infos := range infoss {
 		for _, info := range infos {
-			if info.RequestType != nil || info.ResponseType != nil {
+			if info.RequestType.Local() || info.ResponseType.Local() {
 				return true
 			}
 		}
@@ -25,10 +25,10 @@ func needsStrings(infoss map[inspects.Receiver]map[string]inspects.Info) bool {
 func needsJson(infoss map[inspects.Receiver]map[string]inspects.Info) bool {
 	for _, infos := range infoss {
 		for _, info := range infos {
-			if info.RequestType != nil && len(info.RequestType.Params.Json) > 0 {
+			if info.RequestType.Local() && len(info.RequestType.Params.Json) > 0 {
 				return true
 			}
-			if info.ResponseType != nil && len(info.ResponseType.Params.Json) > 0 {
+			if info.ResponseType.Local() && len(info.ResponseType.Params.Json) > 0 {
 				return true
 			}
 		}
@@ -39,10 +39,36 @@ func needsJson(infoss map[inspects.Receiver]map[string]inspects.Info) bool {
 func needsBytes(infoss map[inspects.Receiver]map[string]inspects.Info) bool {
 	for _, infos := range infoss {
 		for _, info := range infos {
-			if info.RequestType != nil && info.RequestType.ContainsBody {
+			if info.RequestType.Local() && info.RequestType.ContainsBody && info.RequestType.Body == "" && len(info.RequestType.Params.File) == 0 {
 				return true
 			}
-			if info.ResponseType != nil && info.ResponseType.ContainsBody {
+		}
+	}
+	return false
+}
+
+// bq.Build needs for writing multipart bodies
+func needsMultipart(infoss map[inspects.Receiver]map[string]inspects.Info) bool {
+	for _, infos := range infoss {
+		for _, info := range infos {
+			if info.RequestType.Local() && len(info.RequestType.Params.File) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// bq.Build needs for escaping route and query values, and for encoding
+// urlencoded form bodies
+func needsUrl(infoss map[inspects.Receiver]map[string]inspects.Info) bool {
+	for _, infos := range infoss {
+		for _, info := range infos {
+			if !info.RequestType.Local() {
+				continue
+			}
+			ps := info.RequestType.Params
+			if len(ps.Route) > 0 || len(ps.Query) > 0 || (len(ps.Form) > 0 && len(ps.File) == 0) {
 				return true
 			}
 		}
@@ -50,6 +76,43 @@ func needsBytes(infoss map[inspects.Receiver]map[string]inspects.Info) bool {
 	return false
 }
 
+// unseen returns the specs of imports that are not seen yet
+func unseen(iss []*ast.ImportSpec, seen map[string]bool) []ast.Spec {
+	specs := []ast.Spec{}
+	for _, is := range iss {
+		if !seen[is.Path.Value] {
+			seen[is.Path.Value] = true
+			spec := &ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: is.Path.Value}}
+			if is.Name != nil {
+				spec.Name = ast.NewIdent(is.Name.Name)
+			}
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
+// the imports the types of handler factory parameters need in listers, and
+// the types of json fields need in the views of bodies
+func args(infoss map[inspects.Receiver]map[string]inspects.Info, imports []ast.Spec) []ast.Spec {
+	seen := map[string]bool{}
+	for _, is := range imports {
+		seen[is.(*ast.ImportSpec).Path.Value] = true
+	}
+	specs := []ast.Spec{}
+	for _, infos := range infoss {
+		for _, info := range infos {
+			specs = append(specs, unseen(info.Imports, seen)...)
+			for _, bti := range []*inspects.BindingTypeInfo{info.RequestType, info.ResponseType} {
+				if bti.Local() && bti.Viewed() {
+					specs = append(specs, unseen(bti.Imports, seen)...)
+				}
+			}
+		}
+	}
+	return specs
+}
+
 func List(infoss map[inspects.Receiver]map[string]inspects.Info) []ast.Spec {
 	imports := []ast.Spec{
 		&ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: `"fmt"`}},
@@ -66,11 +129,23 @@ func List(infoss map[inspects.Receiver]map[string]inspects.Info) []ast.Spec {
 			&ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: `"encoding/json"`}},
 		)
 	}
+	if needsMultipart(infoss) {
+		imports = append(imports,
+			&ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: `"io"`}},
+			&ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: `"mime/multipart"`}},
+		)
+	}
+	if needsUrl(infoss) {
+		imports = append(imports,
+			&ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: `"net/url"`}},
+		)
+	}
 	if needsStrings(infoss) {
 		imports = append(imports,
 			&ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: `"strings"`}},
 		)
 	}
+	imports = append(imports, args(infoss, imports)...)
 	sort.Imports(imports)
 	return imports
 }
@@ -15,7 +15,7 @@ func quotes(s string) string {
 func needsJoin(infoss map[inspects.Receiver]map[string]inspects.Info) bool {
 	for _, handlers := range infoss {
 		for _, info := range handlers {
-			if info.RequestType != nil {
+			if info.RequestType.Local() {
 				return true
 			}
 		}
@@ -35,7 +35,7 @@ var join = &ast.FuncDecl{
 	Body: &ast.BlockStmt{
 		List: []ast.Stmt{
 			&ast.AssignStmt{
-				Lhs: []ast.Expr{&ast.Ident{Name: "url"}},
+				Lhs: []ast.Expr{&ast.Ident{Name: "joined"}},
 				Tok: token.DEFINE,
 				Rhs: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: quotes("")}},
 			},
@@ -60,21 +60,21 @@ var join = &ast.FuncDecl{
 							},
 							Body: &ast.BlockStmt{List: []ast.Stmt{
 								&ast.AssignStmt{
-									Lhs: []ast.Expr{&ast.Ident{Name: "url"}},
+									Lhs: []ast.Expr{&ast.Ident{Name: "joined"}},
 									Tok: token.ADD_ASSIGN,
 									Rhs: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: quotes("/")}},
 								},
 							}},
 						},
 						&ast.AssignStmt{
-							Lhs: []ast.Expr{&ast.Ident{Name: "url"}},
+							Lhs: []ast.Expr{&ast.Ident{Name: "joined"}},
 							Tok: token.ADD_ASSIGN,
 							Rhs: []ast.Expr{&ast.Ident{Name: "segment"}},
 						},
 					},
 				},
 			},
-			&ast.ReturnStmt{Results: []ast.Expr{&ast.Ident{Name: "url"}}},
+			&ast.ReturnStmt{Results: []ast.Expr{&ast.Ident{Name: "joined"}}},
 		},
 	},
 }
@@ -121,13 +121,166 @@ var firstOrZero = &ast.FuncDecl{
 	},
 }
 
+// escapeSegments path-escapes the segments of the value of a "{x...}"
+// wildcard one by one, so the slashes between them are kept
+var escapeSegments = &ast.FuncDecl{
+	Name: &ast.Ident{Name: "escapeSegments"},
+	Type: &ast.FuncType{
+		Params: &ast.FieldList{List: []*ast.Field{{
+			Names: []*ast.Ident{{Name: "value"}},
+			Type:  &ast.Ident{Name: "string"},
+		}}},
+		Results: &ast.FieldList{List: []*ast.Field{{Type: &ast.Ident{Name: "string"}}}},
+	},
+	Body: &ast.BlockStmt{
+		List: []ast.Stmt{
+			&ast.AssignStmt{
+				Lhs: []ast.Expr{&ast.Ident{Name: "segments"}},
+				Tok: token.DEFINE,
+				Rhs: []ast.Expr{&ast.CallExpr{
+					Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "strings"}, Sel: &ast.Ident{Name: "Split"}},
+					Args: []ast.Expr{&ast.Ident{Name: "value"}, &ast.BasicLit{Kind: token.STRING, Value: quotes("/")}},
+				}},
+			},
+			&ast.RangeStmt{
+				Key: &ast.Ident{Name: "i"},
+				Tok: token.DEFINE,
+				X:   &ast.Ident{Name: "segments"},
+				Body: &ast.BlockStmt{
+					List: []ast.Stmt{
+						&ast.AssignStmt{
+							Lhs: []ast.Expr{&ast.IndexExpr{X: &ast.Ident{Name: "segments"}, Index: &ast.Ident{Name: "i"}}},
+							Tok: token.ASSIGN,
+							Rhs: []ast.Expr{&ast.CallExpr{
+								Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "url"}, Sel: &ast.Ident{Name: "PathEscape"}},
+								Args: []ast.Expr{&ast.IndexExpr{X: &ast.Ident{Name: "segments"}, Index: &ast.Ident{Name: "i"}}},
+							}},
+						},
+					},
+				},
+			},
+			&ast.ReturnStmt{Results: []ast.Expr{&ast.CallExpr{
+				Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "strings"}, Sel: &ast.Ident{Name: "Join"}},
+				Args: []ast.Expr{&ast.Ident{Name: "segments"}, &ast.BasicLit{Kind: token.STRING, Value: quotes("/")}},
+			}}},
+		},
+	},
+}
+
+func needsEscapeSegments(infoss map[inspects.Receiver]map[string]inspects.Info) bool {
+	for _, infos := range infoss {
+		for _, info := range infos {
+			if !info.RequestType.Local() {
+				continue
+			}
+			for rp := range info.RequestType.Params.Route {
+				if seg, ok := info.Pattern.Wildcard(rp); ok && seg.Multi {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// deepObject collects the values of "prefix[key]" params by their keys
+var deepObject = &ast.FuncDecl{
+	Name: &ast.Ident{Name: "deepObject"},
+	Type: &ast.FuncType{
+		Params: &ast.FieldList{List: []*ast.Field{
+			{
+				Names: []*ast.Ident{{Name: "q"}},
+				Type:  &ast.SelectorExpr{X: &ast.Ident{Name: "url"}, Sel: &ast.Ident{Name: "Values"}},
+			},
+			{
+				Names: []*ast.Ident{{Name: "prefix"}},
+				Type:  &ast.Ident{Name: "string"},
+			},
+		}},
+		Results: &ast.FieldList{List: []*ast.Field{{Type: &ast.MapType{Key: &ast.Ident{Name: "string"}, Value: &ast.Ident{Name: "string"}}}}},
+	},
+	Body: &ast.BlockStmt{
+		List: []ast.Stmt{
+			&ast.AssignStmt{
+				Lhs: []ast.Expr{&ast.Ident{Name: "object"}},
+				Tok: token.DEFINE,
+				Rhs: []ast.Expr{&ast.CompositeLit{Type: &ast.MapType{Key: &ast.Ident{Name: "string"}, Value: &ast.Ident{Name: "string"}}}},
+			},
+			&ast.RangeStmt{
+				Key:   &ast.Ident{Name: "param"},
+				Value: &ast.Ident{Name: "values"},
+				Tok:   token.DEFINE,
+				X:     &ast.Ident{Name: "q"},
+				Body: &ast.BlockStmt{
+					List: []ast.Stmt{
+						&ast.AssignStmt{
+							Lhs: []ast.Expr{&ast.Ident{Name: "key"}, &ast.Ident{Name: "ok"}},
+							Tok: token.DEFINE,
+							Rhs: []ast.Expr{&ast.CallExpr{
+								Fun: &ast.SelectorExpr{X: &ast.Ident{Name: "strings"}, Sel: &ast.Ident{Name: "CutPrefix"}},
+								Args: []ast.Expr{
+									&ast.Ident{Name: "param"},
+									&ast.BinaryExpr{X: &ast.Ident{Name: "prefix"}, Op: token.ADD, Y: &ast.BasicLit{Kind: token.STRING, Value: quotes("[")}},
+								},
+							}},
+						},
+						&ast.IfStmt{
+							Cond: &ast.BinaryExpr{
+								X: &ast.BinaryExpr{
+									X:  &ast.Ident{Name: "ok"},
+									Op: token.LAND,
+									Y: &ast.CallExpr{
+										Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "strings"}, Sel: &ast.Ident{Name: "HasSuffix"}},
+										Args: []ast.Expr{&ast.Ident{Name: "key"}, &ast.BasicLit{Kind: token.STRING, Value: quotes("]")}},
+									},
+								},
+								Op: token.LAND,
+								Y: &ast.BinaryExpr{
+									X:  &ast.CallExpr{Fun: &ast.Ident{Name: "len"}, Args: []ast.Expr{&ast.Ident{Name: "values"}}},
+									Op: token.GTR,
+									Y:  &ast.BasicLit{Kind: token.INT, Value: "0"},
+								},
+							},
+							Body: &ast.BlockStmt{List: []ast.Stmt{
+								&ast.AssignStmt{
+									Lhs: []ast.Expr{&ast.IndexExpr{
+										X: &ast.Ident{Name: "object"},
+										Index: &ast.CallExpr{
+											Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "strings"}, Sel: &ast.Ident{Name: "TrimSuffix"}},
+											Args: []ast.Expr{&ast.Ident{Name: "key"}, &ast.BasicLit{Kind: token.STRING, Value: quotes("]")}},
+										},
+									}},
+									Tok: token.ASSIGN,
+									Rhs: []ast.Expr{&ast.IndexExpr{X: &ast.Ident{Name: "values"}, Index: &ast.BasicLit{Kind: token.INT, Value: "0"}}},
+								},
+							}},
+						},
+					},
+				},
+			},
+			&ast.ReturnStmt{Results: []ast.Expr{&ast.Ident{Name: "object"}}},
+		},
+	},
+}
+
+func needsDeepObject(infoss map[inspects.Receiver]map[string]inspects.Info) bool {
+	for _, infos := range infoss {
+		for _, info := range infos {
+			if info.RequestType.Local() && len(info.RequestType.Objects) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func needsFirstOrZero(infoss map[inspects.Receiver]map[string]inspects.Info) bool {
 	for _, infos := range infoss {
 		for _, info := range infos {
-			if info.RequestType != nil && len(info.RequestType.Params.Form) > 0 {
+			if info.RequestType.Local() && len(info.RequestType.Params.Form) > 0 {
 				return true
 			}
-			if info.ResponseType != nil && len(info.ResponseType.Params.Form) > 0 {
+			if info.ResponseType.Local() && len(info.ResponseType.Params.Form) > 0 {
 				return true
 			}
 		}
@@ -140,6 +293,12 @@ func Produce(infoss map[inspects.Receiver]map[string]inspects.Info) []ast.Decl {
 	if needsJoin(infoss) {
 		decls = append(decls, join)
 	}
+	if needsEscapeSegments(infoss) {
+		decls = append(decls, escapeSegments)
+	}
+	if needsDeepObject(infoss) {
+		decls = append(decls, deepObject)
+	}
 	if needsFirstOrZero(infoss) {
 		decls = append(decls, firstOrZero)
 	}
@@ -2,6 +2,7 @@ package helpers
 
 import (
 	"cmp"
+	"errors"
 	"flag"
 	"fmt"
 	"go/ast"
@@ -9,11 +10,15 @@ import (
 	"io"
 	"os"
 	"slices"
+	"strings"
 
 	"go.ufukty.com/gohandlers/cmd/gohandlers/commands/helpers/internal/construct"
 	"go.ufukty.com/gohandlers/cmd/gohandlers/commands/helpers/internal/imports"
 	"go.ufukty.com/gohandlers/cmd/gohandlers/commands/helpers/internal/utilities"
+	"go.ufukty.com/gohandlers/cmd/gohandlers/internal/config"
 	"go.ufukty.com/gohandlers/cmd/gohandlers/internal/pretty"
+	"go.ufukty.com/gohandlers/cmd/gohandlers/internal/report"
+	"go.ufukty.com/gohandlers/cmd/gohandlers/internal/targets"
 	"go.ufukty.com/gohandlers/pkg/inspects"
 )
 
@@ -22,7 +27,15 @@ type Args struct {
 	Out     string
 	Recv    string
 	PkgName string
+	Types   bool
 	Verbose bool
+
+	Diagnostics    string
+	DiagnosticsOut string
+	Werror         bool
+
+	Config      string // the config file to read the conventions from
+	Conventions inspects.Conventions
 }
 
 func filterByRecv(infoss map[inspects.Receiver]map[string]inspects.Info, recvt string) (map[inspects.Receiver]map[string]inspects.Info, error) {
@@ -55,35 +68,30 @@ func ordered(infoss map[inspects.Receiver]map[string]inspects.Info) []funcrecv {
 	return o
 }
 
-func Main() error {
-	args := &Args{}
-	flag.StringVar(&args.Dir, "dir", ".", "the source directory contains Go files for handlers and binding types")
-	flag.StringVar(&args.Out, "out", "gh.go", "the path for output file")
-	flag.StringVar(&args.PkgName, "pkg", "", "override the package name resolved from Go files")
-	flag.StringVar(&args.Recv, "recv", "", "ignore handlers defined on other receivers")
-	flag.BoolVar(&args.Verbose, "v", false, "prints additional information")
-	flag.Parse()
-
-	if args.Dir == "" {
-		flag.PrintDefaults()
-		return fmt.Errorf("bad arguments")
-	}
-
-	infoss, pkgName, err := inspects.Dir(args.Dir, args.Verbose)
-	if err != nil {
-		return fmt.Errorf("inspecting the directory: %w", err)
-	}
+// Generate writes the helpers file for the inspected package in dir.
+// Packages without handlers are skipped when they are matched by a pattern.
+func Generate(args *Args, pkg *inspects.Package, dir, out string, matched bool) error {
+	infoss, pkgName := pkg.Handlers, pkg.Name
 
 	if args.PkgName != "" {
 		pkgName = args.PkgName
 	}
 
 	if args.Recv != "" {
+		var err error
 		infoss, err = filterByRecv(infoss, args.Recv)
-		if err != nil {
+		if err != nil && !matched {
 			return fmt.Errorf("filtering binding types based on the receiver type of handlers: %w", err)
 		}
 	}
+
+	if matched && len(infoss) == 0 {
+		if args.Verbose {
+			fmt.Printf("skipping %s as it has no handlers\n", dir)
+		}
+		return nil
+	}
+
 	f := &ast.File{
 		Name: ast.NewIdent(pkgName),
 		Decls: []ast.Decl{
@@ -93,17 +101,30 @@ func Main() error {
 
 	f.Decls = append(f.Decls, construct.Listers(infoss)...)
 	f.Decls = append(f.Decls, utilities.Produce(infoss)...)
+	// binding types shared by handlers get their methods once, for the first
+	requests, responses := map[string]bool{}, map[string]bool{}
 	for _, o := range ordered(infoss) {
 		i := infoss[o.receiver][o.handler]
-		if i.RequestType != nil {
+		if i.RequestType.Local() && !requests[i.RequestType.Typename] {
+			requests[i.RequestType.Typename] = true
+			if view, ok := construct.View(i.RequestType); ok {
+				f.Decls = append(f.Decls, view)
+			}
 			f.Decls = append(f.Decls, construct.BqBuild(i))
-			if len(i.RequestType.Params.Form) > 0 {
+			if len(i.RequestType.Params.File) > 0 {
+				f.Decls = append(f.Decls, construct.BqMarshalFormData(i))
+			}
+			if len(i.RequestType.Params.Form) > 0 || len(i.RequestType.Params.File) > 0 {
 				f.Decls = append(f.Decls, construct.BqUnmarshalFormData(i))
 			}
 			f.Decls = append(f.Decls, construct.BqParse(i))
 			f.Decls = append(f.Decls, construct.BqValidate(i.RequestType))
 		}
-		if i.ResponseType != nil {
+		if i.ResponseType.Local() && !responses[i.ResponseType.Typename] {
+			responses[i.ResponseType.Typename] = true
+			if view, ok := construct.View(i.ResponseType); ok {
+				f.Decls = append(f.Decls, view)
+			}
 			f.Decls = append(f.Decls, construct.BsWrite(i))
 			f.Decls = append(f.Decls, construct.BsParse(i))
 		}
@@ -113,7 +134,7 @@ func Main() error {
 	if err != nil {
 		return fmt.Errorf("pretty printing: %w", err)
 	}
-	fh, err := os.Create(args.Out)
+	fh, err := os.Create(out)
 	if err != nil {
 		return fmt.Errorf("creating output file: %w", err)
 	}
@@ -125,3 +146,92 @@ func Main() error {
 
 	return nil
 }
+
+// generate inspects the package in dir and writes its helpers file
+func generate(args *Args, dir, out string, matched bool) ([]inspects.Diagnostic, error) {
+	inspect := args.Conventions.Dir
+	if args.Types {
+		inspect = args.Conventions.Load
+	}
+
+	pkg, err := inspect(dir)
+	if err != nil {
+		return nil, fmt.Errorf("inspecting the directory: %w", err)
+	}
+	report.Diagnostics(os.Stderr, pkg.Diagnostics, args.Verbose)
+	if args.Verbose {
+		report.Handlers(os.Stdout, pkg.Handlers)
+	}
+
+	return pkg.Diagnostics, Generate(args, pkg, dir, out, matched)
+}
+
+func Main() error {
+	args := &Args{}
+	flag.StringVar(&args.Dir, "dir", ".", "the source directory or a package pattern (eg. ./services/...) for handlers and binding types")
+	flag.StringVar(&args.Out, "out", "gh.go", "the path for output file; relative to each package directory when -dir is a pattern")
+	flag.StringVar(&args.PkgName, "pkg", "", "override the package name resolved from Go files")
+	flag.StringVar(&args.Recv, "recv", "", "ignore handlers defined on other receivers")
+	flag.BoolVar(&args.Types, "typecheck", false, "inspect the package with full type information")
+	flag.StringVar(&args.Config, "config", "", fmt.Sprintf("the config file to read the conventions from (default %q at the module root, if any)", config.Filename))
+	flag.BoolVar(&args.Verbose, "v", false, "prints additional information")
+	flag.StringVar(&args.Diagnostics, "diagnostics", "", fmt.Sprintf("also write diagnostics into a file in one of formats: %s", strings.Join(report.Formats, ", ")))
+	flag.StringVar(&args.DiagnosticsOut, "diagnostics-out", "", "the path for diagnostics file (default \"gohandlers.<format>\")")
+	flag.BoolVar(&args.Werror, "Werror", false, "exit with non-zero status also when there are warnings")
+	flag.Parse()
+
+	if args.Dir == "" {
+		flag.PrintDefaults()
+		return fmt.Errorf("bad arguments")
+	}
+	if args.Diagnostics != "" && !slices.Contains(report.Formats, args.Diagnostics) {
+		flag.PrintDefaults()
+		return fmt.Errorf("unknown diagnostics format: %s", args.Diagnostics)
+	}
+
+	conv, path, err := config.Default(args.Config)
+	if err != nil {
+		return fmt.Errorf("reading conventions: %w", err)
+	}
+	if args.Config == "" && path != "" {
+		fmt.Fprintf(os.Stderr, "using the conventions in %s\n", path)
+	}
+	args.Conventions = conv
+
+	return Run(args)
+}
+
+// Run generates the helpers files for the packages in args.Dir. Error
+// diagnostics fail it after the files are generated, and so do warnings
+// with args.Werror.
+func Run(args *Args) error {
+	matched := inspects.IsPattern(args.Dir)
+	ts, err := targets.List(args.Dir, args.Out, false)
+	if err != nil {
+		return fmt.Errorf("resolving targets: %w", err)
+	}
+
+	diagnostics := []inspects.Diagnostic{}
+	var failed error
+	for _, t := range ts {
+		ds, err := generate(args, t.Dir, t.Out, matched)
+		diagnostics = append(diagnostics, ds...)
+		if err != nil {
+			failed = fmt.Errorf("%s: %w", t, err)
+			break
+		}
+	}
+
+	// written also when a package fails, for the diagnostics found so far
+	if args.Diagnostics != "" {
+		out := cmp.Or(args.DiagnosticsOut, "gohandlers."+args.Diagnostics)
+		if err := report.WriteFile(out, args.Diagnostics, diagnostics); err != nil {
+			return errors.Join(failed, err)
+		}
+	}
+	if failed != nil {
+		return failed
+	}
+
+	return report.Fail(report.Failing(diagnostics, args.Werror), args.Werror)
+}
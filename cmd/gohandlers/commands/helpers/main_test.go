@@ -0,0 +1,108 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"go.ufukty.com/gohandlers/pkg/inspects"
+)
+
+// copyDir copies the files of the fixture into a temporary directory
+// inside the module, so the imports of the fixture resolve for the go
+// command without writing into the testdata. The directory starts with an
+// underscore to keep it out of the ./... patterns.
+func copyDir(t *testing.T, src string) string {
+	t.Helper()
+	dst, err := os.MkdirTemp(".", "_"+filepath.Base(src))
+	if err != nil {
+		t.Fatalf("prep: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dst) })
+	es, err := os.ReadDir(src)
+	if err != nil {
+		t.Fatalf("prep: %v", err)
+	}
+	for _, e := range es {
+		if e.IsDir() {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(src, e.Name()))
+		if err != nil {
+			t.Fatalf("prep: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dst, e.Name()), b, 0o644); err != nil {
+			t.Fatalf("prep: %v", err)
+		}
+	}
+	return dst
+}
+
+// TestGenerate_roundtrip generates the helpers for the copies of testdata
+// packages and runs their tests, which check the requests made with Build
+// are parsed back into the same values with Parse
+func TestGenerate_roundtrip(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go command")
+	}
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	fixtures := []string{"testdata/roundtrip"}
+	for _, fixture := range fixtures {
+		t.Run(filepath.Base(fixture), func(t *testing.T) {
+			dir := copyDir(t, fixture)
+			pkg, err := inspects.Conventions{}.Dir(dir)
+			if err != nil {
+				t.Fatalf("prep: %v", err)
+			}
+			if len(pkg.Diagnostics) > 0 {
+				t.Fatalf("prep: unexpected diagnostics: %v", pkg.Diagnostics)
+			}
+			if err := Generate(&Args{}, pkg, dir, filepath.Join(dir, "gh.go"), false); err != nil {
+				t.Fatalf("act: %v", err)
+			}
+			cmd := exec.Command(gobin, "test", "./"+dir)
+			if output, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("expected the generated helpers to pass the tests: %v\n%s", err, output)
+			}
+			if _, err := os.Stat(filepath.Join(fixture, "gh.go")); !os.IsNotExist(err) {
+				t.Errorf("expected the fixture to be left untouched, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRun_errors(t *testing.T) {
+	type tc struct {
+		dir      string
+		werror   bool
+		expected string
+	}
+	tcs := []tc{
+		{"testdata/conflicts", false, "found 1 errors"},
+		{"testdata/conflicts", true, "found 1 warnings or errors"},
+		{"testdata/warnings", false, ""},
+		{"testdata/warnings", true, "found 1 warnings or errors"},
+		{"testdata/invalid", false, "found 1 errors"},
+	}
+	for _, tc := range tcs {
+		t.Run(fmt.Sprintf("%s werror=%t", filepath.Base(tc.dir), tc.werror), func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "gh.go")
+			err := Run(&Args{Dir: tc.dir, Out: out, Werror: tc.werror})
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+			if _, err := os.Stat(out); err != nil {
+				t.Errorf("expected the file to be generated: %v", err)
+			}
+		})
+	}
+}
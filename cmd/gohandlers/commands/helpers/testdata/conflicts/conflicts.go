@@ -0,0 +1,11 @@
+package conflicts
+
+import "net/http"
+
+type Pets struct{}
+
+// GET /pets/{id}
+func (p *Pets) Get(w http.ResponseWriter, r *http.Request) {}
+
+// GET /pets/{id}
+func (p *Pets) Visit(w http.ResponseWriter, r *http.Request) {}
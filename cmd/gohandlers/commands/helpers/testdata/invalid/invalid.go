@@ -0,0 +1,8 @@
+package invalid
+
+import "net/http"
+
+type Pets struct{}
+
+// GET /pets/{id
+func (p *Pets) Get(w http.ResponseWriter, r *http.Request) {}
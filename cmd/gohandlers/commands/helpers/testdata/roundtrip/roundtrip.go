@@ -0,0 +1,165 @@
+package roundtrip
+
+import (
+	"io"
+	"net/http"
+
+	"go.ufukty.com/gohandlers/pkg/types/basics"
+)
+
+type Pets struct{}
+
+type GetPetRequest struct {
+	Name basics.String `route:"name"`
+	Tag  basics.String `query:"tag"`
+	Sort basics.String `query:"sort"`
+}
+
+// GetPetResponse only has json fields, so it is encoded as a whole
+type GetPetResponse struct {
+	Name     basics.String `json:"name"`
+	Nickname string        `json:",omitempty"`
+	Age      int           `json:"age,string"`
+	Owner    string
+}
+
+// GET /pets/{name}
+func (p *Pets) GetPet(w http.ResponseWriter, r *http.Request) {
+	_ = &GetPetRequest{}
+	_ = &GetPetResponse{}
+}
+
+type DownloadRequest struct {
+	Path basics.String `route:"path,default=index.html"`
+}
+
+// GET /files/{path...}
+func (p *Pets) Download(w http.ResponseWriter, r *http.Request) {
+	_ = &DownloadRequest{}
+}
+
+type RenamePetRequest struct {
+	Name    basics.String `route:"name"`
+	NewName basics.String `form:"new-name,required"`
+	Reason  basics.String `form:"reason,default=unknown"`
+}
+
+// POST /pets/{name}/rename
+func (p *Pets) RenamePet(w http.ResponseWriter, r *http.Request) {
+	_ = &RenamePetRequest{}
+}
+
+type ListPetsRequest struct {
+	Tags   basics.Strings   `query:"tag,repeat"`
+	Colors basics.Strings   `query:"colors,comma"`
+	Sort   basics.String    `query:"sort"`
+	Filter basics.StringMap `query:"filter,deep"`
+	Age    Range            `query:"age,deep"`
+}
+
+// Range is bound to "age[min]" and "age[max]"
+type Range struct {
+	Min, Max string
+}
+
+func (r *Range) FromQueryObject(m map[string]string) error {
+	r.Min, r.Max = m["min"], m["max"]
+	return nil
+}
+
+func (r Range) ToQueryObject() (map[string]string, error) {
+	m := map[string]string{}
+	if r.Min != "" {
+		m["min"] = r.Min
+	}
+	if r.Max != "" {
+		m["max"] = r.Max
+	}
+	return m, nil
+}
+
+func (r Range) Validate() any { return nil }
+
+// GET /pets
+func (p *Pets) ListPets(w http.ResponseWriter, r *http.Request) {
+	_ = &ListPetsRequest{}
+}
+
+type SearchPetsRequest struct {
+	Owner  basics.String  `query:"owner,required"`
+	Limit  basics.Int     `query:"limit,default=10"`
+	Colors basics.Strings `query:"colors,comma,default=black,white"`
+	Tenant basics.String  `header:"X-Tenant,required"`
+	Lang   basics.String  `header:"Accept-Language,default=en"`
+}
+
+// GET /search
+func (p *Pets) SearchPets(w http.ResponseWriter, r *http.Request) {
+	_ = &SearchPetsRequest{}
+}
+
+type CreatePetRequest struct {
+	Name basics.String  `json:"name,omitempty"`
+	Tags basics.Strings `json:",omitempty"`
+	Note string         `json:"-"`
+}
+
+type CreatePetResponse struct {
+	Status   int           `status:""`
+	Location basics.String `header:"Location"`
+	Session  basics.String `cookie:"session"`
+	Id       basics.String `json:"id"`
+	Name     basics.String `json:"name"`
+	Age      int           `json:"age,string,omitempty"`
+}
+
+// POST /pets
+// gh:status 201
+func (p *Pets) CreatePet(w http.ResponseWriter, r *http.Request) {
+	_ = &CreatePetRequest{}
+	_ = &CreatePetResponse{}
+}
+
+type AdoptPetRequest struct {
+	Tenant  basics.String `header:"X-Tenant-Id"`
+	Key     basics.String `header:"Idempotency-Key"`
+	Session basics.String `cookie:"session"`
+	Csrf    basics.String `cookie:"csrf_token"`
+	Name    basics.String `json:"name"`
+}
+
+// POST /adopt
+func (p *Pets) AdoptPet(w http.ResponseWriter, r *http.Request) {
+	_ = &AdoptPetRequest{}
+}
+
+type GetSessionRequest struct {
+	Session basics.String `cookie:"session"`
+}
+
+// GET /session
+func (p *Pets) GetSession(w http.ResponseWriter, r *http.Request) {
+	_ = &GetSessionRequest{}
+}
+
+type UploadPhotoRequest struct {
+	Name    basics.String `route:"name"`
+	Caption basics.String `form:"caption"`
+	Rating  basics.Int    `form:"rating"`
+	Album   basics.String `form:"album,default=misc"`
+	Photo   basics.File   `file:"photo"`
+}
+
+// POST /pets/{name}/photo
+func (p *Pets) UploadPhoto(w http.ResponseWriter, r *http.Request) {
+	_ = &UploadPhotoRequest{}
+}
+
+type ImportPetsRequest struct {
+	Items io.Reader `body:"application/x-ndjson"`
+}
+
+// POST /import
+func (p *Pets) ImportPets(w http.ResponseWriter, r *http.Request) {
+	_ = &ImportPetsRequest{}
+}
@@ -0,0 +1,444 @@
+package roundtrip
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.ufukty.com/gohandlers/pkg/gohandlers"
+	"go.ufukty.com/gohandlers/pkg/types/basics"
+)
+
+// serve builds the request, serves it with the handler pattern and
+// returns the error of parsing it back with the generated Parse
+func serve(t *testing.T, pattern string, build func(host string) (*http.Request, error), parse func(*http.Request) error) error {
+	t.Helper()
+	rq, err := build("http://localhost")
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	matched := false
+	mux := http.NewServeMux()
+	mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		matched = true
+		err = parse(r)
+	})
+	mux.ServeHTTP(httptest.NewRecorder(), rq)
+	if !matched {
+		t.Fatalf("%s didn't match %s", pattern, rq.URL)
+	}
+	return err
+}
+
+// roundtrip is [serve] for the requests expected to be parsed
+func roundtrip(t *testing.T, pattern string, build func(host string) (*http.Request, error), parse func(*http.Request) error) {
+	t.Helper()
+	if err := serve(t, pattern, build, parse); err != nil {
+		t.Errorf("Parse: %v", err)
+	}
+}
+
+var values = []string{
+	"garfield",
+	"tom & jerry",
+	"who?",
+	"a/b",
+	"50% off",
+	"#1 cat",
+	"çılgın kedi",
+	"plus+sign",
+	"semi;colon=eq",
+}
+
+func TestGetPet(t *testing.T) {
+	for _, v := range values {
+		t.Run(v, func(t *testing.T) {
+			sent := GetPetRequest{Name: basics.String(v), Tag: basics.String(v), Sort: basics.String("name asc")}
+			got := GetPetRequest{}
+			roundtrip(t, "GET /pets/{name}", sent.Build, got.Parse)
+			if got != sent {
+				t.Errorf("expected %#v got %#v", sent, got)
+			}
+		})
+	}
+}
+
+func TestDownload(t *testing.T) {
+	paths := append(values, "dir/sub dir/file?.txt", "a%2Fb/c")
+	for _, v := range paths {
+		t.Run(v, func(t *testing.T) {
+			sent := DownloadRequest{Path: basics.String(v)}
+			got := DownloadRequest{}
+			roundtrip(t, "GET /files/{path...}", sent.Build, got.Parse)
+			if got != sent {
+				t.Errorf("expected %#v got %#v", sent, got)
+			}
+		})
+	}
+}
+
+func TestRenamePet(t *testing.T) {
+	for _, v := range values {
+		t.Run(v, func(t *testing.T) {
+			sent := RenamePetRequest{Name: basics.String(v), NewName: basics.String(v), Reason: basics.String(v)}
+			got := RenamePetRequest{}
+			roundtrip(t, "POST /pets/{name}/rename", sent.Build, got.Parse)
+			if got != sent {
+				t.Errorf("expected %#v got %#v", sent, got)
+			}
+		})
+	}
+}
+
+func TestListPets(t *testing.T) {
+	tcs := map[string]ListPetsRequest{
+		"empty":   {},
+		"single":  {Tags: basics.Strings{"cute"}, Colors: basics.Strings{"black"}},
+		"many":    {Tags: basics.Strings{"cute", "tom & jerry", "a=b"}, Colors: basics.Strings{"black", "white", "50% grey"}, Sort: "name"},
+		"escaped": {Tags: basics.Strings{"a,b", "?"}, Colors: basics.Strings{"#fff", "a b"}},
+		"filter":  {Filter: basics.StringMap{"name": "tom & jerry", "owner[id]": "1", "a b": "c=d"}},
+		"range":   {Age: Range{Min: "1"}, Filter: basics.StringMap{"age": "2"}},
+	}
+	for name, sent := range tcs {
+		t.Run(name, func(t *testing.T) {
+			got := ListPetsRequest{}
+			roundtrip(t, "GET /pets", sent.Build, got.Parse)
+			if !reflect.DeepEqual(got, sent) {
+				t.Errorf("expected %#v got %#v", sent, got)
+			}
+		})
+	}
+}
+
+func TestListPets_commaInValue(t *testing.T) {
+	_, err := (ListPetsRequest{Colors: basics.Strings{"black", "black,white"}}).Build("http://localhost")
+	if err == nil || !strings.Contains(err.Error(), `ListPetsRequest.Colors: value "black,white" contains a comma`) {
+		t.Errorf("expected an error for the comma in the comma separated value, got %v", err)
+	}
+}
+
+func TestDownload_default(t *testing.T) {
+	got := DownloadRequest{}
+	roundtrip(t, "GET /files/{path...}", DownloadRequest{}.Build, got.Parse)
+	if got.Path != "index.html" {
+		t.Errorf("expected the default, got %q", got.Path)
+	}
+}
+
+// form builders send all fields, so the options are checked with the forms
+// sent by others, like browsers
+func TestRenamePet_options(t *testing.T) {
+	form := func(body string) func(string) (*http.Request, error) {
+		return func(host string) (*http.Request, error) {
+			rq := httptest.NewRequest("POST", host+"/pets/tom/rename", strings.NewReader(body))
+			rq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			return rq, nil
+		}
+	}
+
+	got := RenamePetRequest{}
+	roundtrip(t, "POST /pets/{name}/rename", form("new-name=garfield"), got.Parse)
+	expected := RenamePetRequest{Name: "tom", NewName: "garfield", Reason: "unknown"}
+	if got != expected {
+		t.Errorf("expected %#v got %#v", expected, got)
+	}
+
+	err := serve(t, "POST /pets/{name}/rename", form("reason=bored"), (&RenamePetRequest{}).Parse)
+	if !errors.Is(err, gohandlers.ErrMissing) {
+		t.Errorf("expected ErrMissing, got %v", err)
+	}
+}
+
+func TestSearchPets(t *testing.T) {
+	type tc struct {
+		sent, expected SearchPetsRequest
+	}
+	tcs := map[string]tc{
+		"defaults": {
+			SearchPetsRequest{Owner: "jon", Tenant: "acme"},
+			SearchPetsRequest{Owner: "jon", Tenant: "acme", Limit: 10, Colors: basics.Strings{"black", "white"}, Lang: "en"},
+		},
+		"sent": {
+			SearchPetsRequest{Owner: "jon", Tenant: "acme", Limit: 5, Colors: basics.Strings{"orange"}, Lang: "tr"},
+			SearchPetsRequest{Owner: "jon", Tenant: "acme", Limit: 5, Colors: basics.Strings{"orange"}, Lang: "tr"},
+		},
+	}
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			got := SearchPetsRequest{}
+			roundtrip(t, "GET /search", tc.sent.Build, got.Parse)
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %#v got %#v", tc.expected, got)
+			}
+		})
+	}
+
+	for name, sent := range map[string]SearchPetsRequest{
+		"query":  {Tenant: "acme"},
+		"header": {Owner: "jon"},
+	} {
+		t.Run("missing "+name, func(t *testing.T) {
+			err := serve(t, "GET /search", sent.Build, (&SearchPetsRequest{}).Parse)
+			if !errors.Is(err, gohandlers.ErrMissing) {
+				t.Errorf("expected ErrMissing, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCreatePet(t *testing.T) {
+	sent := CreatePetRequest{Name: "garfield", Tags: basics.Strings{"lazy"}, Note: "not sent"}
+	got := CreatePetRequest{}
+	roundtrip(t, "POST /pets", sent.Build, got.Parse)
+	expected := CreatePetRequest{Name: "garfield", Tags: basics.Strings{"lazy"}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %#v got %#v", expected, got)
+	}
+}
+
+func TestAdoptPet(t *testing.T) {
+	sent := AdoptPetRequest{Tenant: "t1", Key: "k1", Session: "s1", Csrf: "c1", Name: "garfield"}
+	rq, err := sent.Build("http://localhost")
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	body, err := io.ReadAll(rq.Body)
+	if err != nil {
+		t.Fatalf("reading the body: %v", err)
+	}
+	if expected := `{"name":"garfield"}` + "\n"; string(body) != expected {
+		t.Errorf("expected the body not to carry the header and cookie fields, %q got %q", expected, body)
+	}
+
+	got := AdoptPetRequest{}
+	roundtrip(t, "POST /adopt", sent.Build, got.Parse)
+	if got != sent {
+		t.Errorf("expected %#v got %#v", sent, got)
+	}
+}
+
+func TestAdoptPet_bodyOverride(t *testing.T) {
+	rq := httptest.NewRequest("POST", "/adopt", strings.NewReader(`{"name":"garfield","Tenant":"other","key":"k2","Session":"s2","csrf":"c2"}`))
+	rq.Header.Set("Content-Type", "application/json")
+	rq.Header.Set("X-Tenant-Id", "t1")
+	rq.Header.Set("Idempotency-Key", "k1")
+	rq.AddCookie(&http.Cookie{Name: "session", Value: "s1"})
+	rq.AddCookie(&http.Cookie{Name: "csrf_token", Value: "c1"})
+	got := AdoptPetRequest{}
+	if err := got.Parse(rq); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	expected := AdoptPetRequest{Tenant: "t1", Key: "k1", Session: "s1", Csrf: "c1", Name: "garfield"}
+	if got != expected {
+		t.Errorf("expected the body keys not to override the header and cookie fields, %#v got %#v", expected, got)
+	}
+}
+
+func TestCreatePetResponse_Write(t *testing.T) {
+	w := httptest.NewRecorder()
+	sent := CreatePetResponse{Location: "/pets/1", Session: "s", Id: "1", Name: "garfield", Age: 3}
+	if err := sent.Write(w); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	got := map[string]any{}
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("decoding: %v", err)
+	}
+	expected := map[string]any{"id": "1", "name": "garfield", "age": "3"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %#v got %#v", expected, got)
+	}
+}
+
+func TestGetPetResponse_Write(t *testing.T) {
+	w := httptest.NewRecorder()
+	if err := (GetPetResponse{Name: "garfield", Age: 3, Owner: "jon"}).Write(w); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	expected := `{"name":"garfield","age":"3","Owner":"jon"}` + "\n"
+	if got := w.Body.String(); got != expected {
+		t.Errorf("expected %q got %q", expected, got)
+	}
+	got := GetPetResponse{}
+	if err := got.Parse(w.Result()); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got != (GetPetResponse{Name: "garfield", Age: 3, Owner: "jon"}) {
+		t.Errorf("expected the fields back, got %#v", got)
+	}
+}
+
+func TestCreatePetResponse_Parse(t *testing.T) {
+	rs := &http.Response{
+		StatusCode: 201,
+		Header: http.Header{
+			"Content-Type": {"application/json"},
+			"Location":     {"/pets/1"},
+			"Set-Cookie":   {"session=s"},
+		},
+		Body: io.NopCloser(strings.NewReader(`{"id":"1","Status":500,"location":"/evil","Session":"evil","age":"3"}`)),
+	}
+	got := CreatePetResponse{}
+	if err := got.Parse(rs); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	expected := CreatePetResponse{Status: 201, Location: "/pets/1", Session: "s", Id: "1", Age: 3}
+	if got != expected {
+		t.Errorf("expected the body keys not to override the other fields, %#v got %#v", expected, got)
+	}
+}
+
+func TestGetSession(t *testing.T) {
+	// http.Cookie quotes the values with spaces and commas
+	for _, v := range []string{"garfield", "tom & jerry", "a,b", "50% off", "semi=eq"} {
+		t.Run(v, func(t *testing.T) {
+			sent := GetSessionRequest{Session: basics.String(v)}
+			got := GetSessionRequest{}
+			roundtrip(t, "GET /session", sent.Build, got.Parse)
+			if got != sent {
+				t.Errorf("expected %#v got %#v", sent, got)
+			}
+		})
+	}
+
+	// instead of the bytes http.Cookie would drop
+	for _, v := range []string{"semi;colon", `"quoted"`, "çılgın kedi"} {
+		t.Run(v, func(t *testing.T) {
+			if _, err := (GetSessionRequest{Session: basics.String(v)}).Build("http://localhost"); err == nil {
+				t.Errorf("expected an error for the invalid cookie value")
+			}
+		})
+	}
+}
+
+func TestUploadPhoto(t *testing.T) {
+	sent := UploadPhotoRequest{
+		Name:    "tom & jerry",
+		Caption: "50% off",
+		Photo:   basics.File{Filename: "cat.png", Content: strings.NewReader("meow")},
+	}
+	got := UploadPhotoRequest{}
+	content := ""
+	roundtrip(t, "POST /pets/{name}/photo", sent.Build, func(r *http.Request) error {
+		if err := got.Parse(r); err != nil {
+			return err
+		}
+		f, err := got.Photo.Header.Open()
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		b, err := io.ReadAll(f)
+		content = string(b)
+		return err
+	})
+	if got.Name != sent.Name || got.Caption != sent.Caption || got.Photo.Filename != "cat.png" {
+		t.Errorf("expected %#v got %#v", sent, got)
+	}
+	if content != "meow" {
+		t.Errorf("expected the content of the file, got %q", content)
+	}
+}
+
+func TestUploadPhoto_missingFields(t *testing.T) {
+	body := bytes.NewBuffer([]byte{})
+	mw := multipart.NewWriter(body)
+	if err := mw.WriteField("caption", "50% off"); err != nil {
+		t.Fatalf("prep: %v", err)
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatalf("prep: %v", err)
+	}
+	rq := httptest.NewRequest("POST", "/pets/garfield/photo", body)
+	rq.Header.Set("Content-Type", mw.FormDataContentType())
+	rq.SetPathValue("name", "garfield")
+	got := UploadPhotoRequest{}
+	if err := got.Parse(rq); err != nil {
+		t.Fatalf("act: %v", err)
+	}
+	if got.Caption != "50% off" || got.Rating != 0 || got.Album != "misc" {
+		t.Errorf("expected the missing fields to be left alone or defaulted, got %#v", got)
+	}
+}
+
+// unsized hides the Len method of the readers
+type unsized struct{ io.Reader }
+
+func TestImportPets(t *testing.T) {
+	type tc struct {
+		body   io.Reader
+		length int64 // 0 is unknown for the requests with a body, which are sent chunked
+	}
+	tcs := map[string]tc{
+		"known":   {strings.NewReader(`{"name":"garfield"}`), 19},
+		"unknown": {unsized{strings.NewReader(`{"name":"garfield"}`)}, 0},
+	}
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			sent := ImportPetsRequest{Items: tc.body}
+			rq, err := sent.Build("http://localhost")
+			if err != nil {
+				t.Fatalf("Build: %v", err)
+			}
+			if rq.ContentLength != tc.length {
+				t.Errorf("expected content length %d got %d", tc.length, rq.ContentLength)
+			}
+			if ct := rq.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+				t.Errorf("expected the content type of the tag, got %q", ct)
+			}
+			got := ImportPetsRequest{}
+			if err := got.Parse(rq); err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			b, err := io.ReadAll(got.Items)
+			if err != nil {
+				t.Fatalf("reading: %v", err)
+			}
+			if string(b) != `{"name":"garfield"}` {
+				t.Errorf("expected the body to be passed as is, got %q", b)
+			}
+		})
+	}
+}
+
+func TestCreatePetResponse(t *testing.T) {
+	type tc struct {
+		sent, expected CreatePetResponse
+	}
+	tcs := map[string]tc{
+		"default status": {
+			CreatePetResponse{Location: "/pets/1", Session: "tom & jerry", Id: "1", Name: "garfield"},
+			CreatePetResponse{Status: 201, Location: "/pets/1", Session: "tom & jerry", Id: "1", Name: "garfield"},
+		},
+		"status": {
+			CreatePetResponse{Status: 202, Location: "/pets/1"},
+			CreatePetResponse{Status: 202, Location: "/pets/1"},
+		},
+	}
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			if err := tc.sent.Write(w); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+			got := CreatePetResponse{}
+			if err := got.Parse(w.Result()); err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("expected %#v got %#v", tc.expected, got)
+			}
+		})
+	}
+
+	if err := (CreatePetResponse{Session: "semi;colon"}).Write(httptest.NewRecorder()); err == nil {
+		t.Errorf("expected an error for the invalid cookie value")
+	}
+}
@@ -0,0 +1,12 @@
+package warnings
+
+import "net/http"
+
+type Pets struct{}
+
+type GetRequest struct {
+	Id string `route:"id"`
+}
+
+// GET /pets/{id}
+func (p *Pets) Get(w http.ResponseWriter, r *http.Request) {}
@@ -10,8 +10,34 @@ import (
 )
 
 type YamlHandler struct {
-	Method string `yaml:"method"`
-	Path   string `yaml:"path"`
+	Method string      `yaml:"method"`
+	Path   string      `yaml:"path"`
+	Status int         `yaml:"status,omitempty"`
+	Tags   []string    `yaml:"tags,omitempty"`
+	Query  []YamlParam `yaml:"query,omitempty"`
+}
+
+// YamlParam is a query parameter of the request. Style is the option of
+// its tag; "repeat", "comma" or "deep", and empty for single values.
+type YamlParam struct {
+	Name  string `yaml:"name"`
+	Style string `yaml:"style,omitempty"`
+}
+
+// query lists the query parameters in the declaration order of the fields
+func query(bq *inspects.BindingTypeInfo) []YamlParam {
+	if bq == nil {
+		return nil
+	}
+	ps := []YamlParam{}
+	for _, qp := range bq.InOrder(bq.Params.Query) {
+		p := YamlParam{Name: qp, Style: bq.Arrays[qp]}
+		if bq.Objects[qp] {
+			p.Style = "deep"
+		}
+		ps = append(ps, p)
+	}
+	return ps
 }
 
 func create(dst string, infoss map[inspects.Receiver]map[string]inspects.Info) error {
@@ -21,6 +47,9 @@ func create(dst string, infoss map[inspects.Receiver]map[string]inspects.Info) e
 			hs[n] = YamlHandler{
 				Method: h.Method,
 				Path:   h.Path,
+				Status: h.Status,
+				Tags:   h.Tags,
+				Query:  query(h.RequestType),
 			}
 		}
 	}
@@ -3,21 +3,45 @@ package yaml
 import (
 	"flag"
 	"fmt"
-	"path/filepath"
+	"os"
 
+	"go.ufukty.com/gohandlers/cmd/gohandlers/internal/config"
+	"go.ufukty.com/gohandlers/cmd/gohandlers/internal/report"
+	"go.ufukty.com/gohandlers/cmd/gohandlers/internal/targets"
 	"go.ufukty.com/gohandlers/pkg/inspects"
 )
 
 type Args struct {
 	Dir     string
 	Out     string
+	Types   bool
 	Verbose bool
+
+	Config      string // the config file to read the conventions from
+	Conventions inspects.Conventions
+}
+
+// Generate writes the yaml file for the inspected package in dir. Packages
+// without handlers are skipped when they are matched by a pattern.
+func Generate(args Args, pkg *inspects.Package, dir, out string, matched bool) error {
+	if matched && len(pkg.Handlers) == 0 {
+		if args.Verbose {
+			fmt.Printf("skipping %s as it has no handlers\n", dir)
+		}
+		return nil
+	}
+	if err := create(out, pkg.Handlers); err != nil {
+		return fmt.Errorf("creating the yaml file: %w", err)
+	}
+	return nil
 }
 
 func Main() error {
 	args := Args{}
-	flag.StringVar(&args.Dir, "dir", "", "the directory contains Go files")
-	flag.StringVar(&args.Out, "out", "gh.yml", "yaml file that will be generated in the 'dir'")
+	flag.StringVar(&args.Dir, "dir", "", "the directory contains Go files or a package pattern (eg. ./services/...)")
+	flag.StringVar(&args.Out, "out", "gh.yml", "yaml file that will be generated in the 'dir' or in each matched package directory")
+	flag.BoolVar(&args.Types, "typecheck", false, "inspect the package with full type information")
+	flag.StringVar(&args.Config, "config", "", fmt.Sprintf("the config file to read the conventions from (default %q at the module root, if any)", config.Filename))
 	flag.BoolVar(&args.Verbose, "v", false, "prints additional information")
 	flag.Parse()
 
@@ -26,15 +50,42 @@ func Main() error {
 		return fmt.Errorf("missing arguments")
 	}
 
-	infoss, _, err := inspects.Dir(args.Dir, args.Verbose)
+	conv, path, err := config.Default(args.Config)
 	if err != nil {
-		return fmt.Errorf("inspecting directory and handlers: %w", err)
+		return fmt.Errorf("reading conventions: %w", err)
+	}
+	if args.Config == "" && path != "" {
+		fmt.Fprintf(os.Stderr, "using the conventions in %s\n", path)
 	}
+	args.Conventions = conv
 
-	err = create(filepath.Join(args.Dir, args.Out), infoss)
+	inspect := args.Conventions.Dir
+	if args.Types {
+		inspect = args.Conventions.Load
+	}
+
+	matched := inspects.IsPattern(args.Dir)
+	ts, err := targets.List(args.Dir, args.Out, true)
 	if err != nil {
-		return fmt.Errorf("creating the yaml file: %w", err)
+		return fmt.Errorf("resolving targets: %w", err)
 	}
 
-	return nil
+	failing := 0
+	for _, t := range ts {
+		pkg, err := inspect(t.Dir)
+		if err != nil {
+			return fmt.Errorf("%s: inspecting directory and handlers: %w", t, err)
+		}
+		report.Diagnostics(os.Stderr, pkg.Diagnostics, args.Verbose)
+		failing += report.Failing(pkg.Diagnostics, false)
+		if args.Verbose {
+			report.Handlers(os.Stdout, pkg.Handlers)
+		}
+
+		if err := Generate(args, pkg, t.Dir, t.Out, matched); err != nil {
+			return fmt.Errorf("%s: %w", t, err)
+		}
+	}
+
+	return report.Fail(failing, false)
 }
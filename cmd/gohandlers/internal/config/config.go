@@ -0,0 +1,207 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.ufukty.com/gohandlers/pkg/inspects"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Filename is the name of the config file expected at the module root
+const Filename = "gohandlers.yml"
+
+// Conventions overrides the defaults of inspection
+type Conventions struct {
+	Verbs       map[string][]string `yaml:"verbs"`            // method -> handler name prefixes
+	Paths       string              `yaml:"paths"`            // path style
+	ContentType string              `yaml:"content-type"`     // of json bodies
+	Memory      string              `yaml:"multipart-memory"` // eg. "32MiB"
+}
+
+type Helpers struct {
+	Out  string `yaml:"out"`
+	Recv string `yaml:"recv"`
+	Pkg  string `yaml:"pkg"`
+}
+
+type Client struct {
+	Out    string `yaml:"out"`
+	Pkg    string `yaml:"pkg"`
+	Import string `yaml:"import"`
+}
+
+type Yaml struct {
+	Out string `yaml:"out"`
+}
+
+// Target is a package or a package pattern with the generators to run on
+type Target struct {
+	Dir       string   `yaml:"dir"`
+	Typecheck bool     `yaml:"typecheck"`
+	Helpers   *Helpers `yaml:"helpers"`
+	Client    *Client  `yaml:"client"`
+	Yaml      *Yaml    `yaml:"yaml"`
+}
+
+type Config struct {
+	Conventions Conventions `yaml:"conventions"`
+	Targets     []Target    `yaml:"targets"`
+}
+
+// Inspects merges the overrides into the defaults. Methods listed in
+// Verbs replace the default prefixes of those methods.
+func (c Conventions) Inspects() (inspects.Conventions, error) {
+	ic := inspects.Conventions{Paths: c.Paths, ContentType: c.ContentType}
+	if c.Memory != "" {
+		m, err := size(c.Memory)
+		if err != nil {
+			return inspects.Conventions{}, fmt.Errorf("multipart-memory: %w", err)
+		}
+		ic.MaxMemory = m
+	}
+	if len(c.Verbs) > 0 {
+		verbs := map[string][]string{}
+		for method, prefixes := range c.Verbs {
+			verbs[strings.ToUpper(method)] = append(verbs[strings.ToUpper(method)], prefixes...)
+		}
+		ic.Verbs = inspects.DefaultVerbs()
+		for prefix, method := range ic.Verbs {
+			if _, ok := verbs[method]; ok {
+				delete(ic.Verbs, prefix)
+			}
+		}
+		for method, prefixes := range verbs {
+			for _, prefix := range prefixes {
+				if prev, ok := ic.Verbs[prefix]; ok && prev != method {
+					return inspects.Conventions{}, fmt.Errorf("verb prefix %s is listed for both %s and %s", prefix, prev, method)
+				}
+				ic.Verbs[prefix] = method
+			}
+		}
+	}
+	if err := ic.Validate(); err != nil {
+		return inspects.Conventions{}, err
+	}
+	return ic, nil
+}
+
+var units = map[string]int64{
+	"":    1,
+	"B":   1,
+	"KB":  1e3,
+	"MB":  1e6,
+	"GB":  1e9,
+	"KiB": 1 << 10,
+	"MiB": 1 << 20,
+	"GiB": 1 << 30,
+}
+
+var sizes = regexp.MustCompile(`^(\d+)\s*([KMG]i?B|B)?$`)
+
+// size parses the byte sizes like "512", "64KB" or "32MiB"
+func size(s string) (int64, error) {
+	ms := sizes.FindStringSubmatch(strings.TrimSpace(s))
+	if ms == nil {
+		return 0, fmt.Errorf("invalid size %q, expected a number of bytes with an optional unit such as KB, MB, KiB or MiB", s)
+	}
+	n, err := strconv.ParseInt(ms[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %w", s, err)
+	}
+	if n > math.MaxInt64/units[ms[2]] {
+		return 0, fmt.Errorf("invalid size %q: too large", s)
+	}
+	return n * units[ms[2]], nil
+}
+
+func (t Target) validate() error {
+	if t.Dir == "" {
+		return fmt.Errorf("missing dir")
+	}
+	if t.Helpers == nil && t.Client == nil && t.Yaml == nil {
+		return fmt.Errorf("%s: no generators", t.Dir)
+	}
+	if t.Client != nil && (t.Client.Out == "" || t.Client.Pkg == "") {
+		return fmt.Errorf("%s: client needs out and pkg", t.Dir)
+	}
+	if t.Client != nil && t.Client.Import != "" && inspects.IsPattern(t.Dir) {
+		return fmt.Errorf("%s: client import can't be set when dir is a pattern", t.Dir)
+	}
+	return nil
+}
+
+// Read parses the config file. Unknown keys are errors to catch typos.
+func Read(path string) (*Config, error) {
+	fh, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening: %w", err)
+	}
+	defer fh.Close()
+	c := &Config{}
+	d := yaml.NewDecoder(fh)
+	d.KnownFields(true)
+	if err := d.Decode(c); err != nil {
+		return nil, fmt.Errorf("decoding: %w", err)
+	}
+	for i, t := range c.Targets {
+		if err := t.validate(); err != nil {
+			return nil, fmt.Errorf("target %d: %w", i, err)
+		}
+	}
+	return c, nil
+}
+
+// Find looks for the config file at the root of module that contains
+// the working directory. Returns empty string when there is none.
+func Find() (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("getting working directory: %w", err)
+	}
+	for dir := wd; ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			path := filepath.Join(dir, Filename)
+			if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+				return "", nil
+			} else if err != nil {
+				return "", fmt.Errorf("checking config file: %w", err)
+			}
+			return path, nil
+		}
+		if dir == filepath.Dir(dir) {
+			return "", nil
+		}
+	}
+}
+
+// Default returns the conventions of the config file at path, or of the
+// config file at the module root when path is empty. The defaults are used
+// when there is none. Used by the commands that otherwise run without the
+// config, which tell the path of the file found.
+func Default(path string) (inspects.Conventions, string, error) {
+	if path == "" {
+		found, err := Find()
+		if err != nil || found == "" {
+			return inspects.Conventions{}, "", err
+		}
+		path = found
+	}
+	c, err := Read(path)
+	if err != nil {
+		return inspects.Conventions{}, "", fmt.Errorf("reading %s: %w", path, err)
+	}
+	conv, err := c.Conventions.Inspects()
+	if err != nil {
+		return inspects.Conventions{}, "", fmt.Errorf("reading %s: %w", path, err)
+	}
+	return conv, path, nil
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.ufukty.com/gohandlers/pkg/inspects"
+)
+
+// write writes the content into a file in a temporary directory
+func write(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("prep: %v", err)
+	}
+	return path
+}
+
+func TestRead(t *testing.T) {
+	type tc struct {
+		content string
+		err     string // substring of the error, empty when valid
+	}
+	tcs := map[string]tc{
+		"valid": {
+			"conventions:\n  paths: kebab\ntargets:\n  - dir: ./...\n    helpers: {}\n    client:\n      out: client/client.go\n      pkg: client\n",
+			"",
+		},
+		"unknown key": {
+			"targets:\n  - dir: pets\n    helper: {}\n",
+			"field helper not found",
+		},
+		"unknown convention": {
+			"conventions:\n  path: kebab\n",
+			"field path not found",
+		},
+		"missing dir": {
+			"targets:\n  - helpers: {}\n",
+			"target 0: missing dir",
+		},
+		"no generators": {
+			"targets:\n  - dir: pets\n",
+			"target 0: pets: no generators",
+		},
+		"client without pkg": {
+			"targets:\n  - dir: pets\n    client:\n      out: client.go\n",
+			"target 0: pets: client needs out and pkg",
+		},
+		"client import with pattern": {
+			"targets:\n  - dir: ./...\n    client:\n      out: client.go\n      pkg: client\n      import: example.com/pets\n",
+			"target 0: ./...: client import can't be set when dir is a pattern",
+		},
+	}
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			_, err := Read(write(t, Filename, tc.content))
+			if tc.err == "" && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+			if tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+				t.Errorf("expected error containing %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestConventions_Inspects(t *testing.T) {
+	c := Conventions{Verbs: map[string][]string{"get": {"Fetch"}, "POST": {"Create", "Make"}}, Memory: "8MiB"}
+	ic, err := c.Inspects()
+	if err != nil {
+		t.Fatalf("act: %v", err)
+	}
+	if ic.MaxMemory != 8<<20 {
+		t.Errorf("expected 8MiB got %d", ic.MaxMemory)
+	}
+	expected := map[string]string{}
+	for prefix, method := range inspects.DefaultVerbs() {
+		if method != "GET" && method != "POST" {
+			expected[prefix] = method
+		}
+	}
+	expected["Fetch"] = "GET"
+	expected["Create"] = "POST"
+	expected["Make"] = "POST"
+	if !maps.Equal(ic.Verbs, expected) {
+		t.Errorf("expected the listed methods to replace their defaults, got %v", ic.Verbs)
+	}
+
+	if ic, err := (Conventions{}).Inspects(); err != nil || ic.Verbs != nil {
+		t.Errorf("expected the defaults without overrides, got %v and %v", ic.Verbs, err)
+	}
+}
+
+func TestConventions_Inspects_invalid(t *testing.T) {
+	tcs := map[string]Conventions{
+		"verb prefix for two methods": {Verbs: map[string][]string{"GET": {"Fetch"}, "POST": {"Fetch"}}},
+		"uncapitalized verb prefix":   {Verbs: map[string][]string{"GET": {"fetch"}}},
+		"unknown method":              {Verbs: map[string][]string{"FETCH": {"Fetch"}}},
+		"unknown path style":          {Paths: "camel"},
+		"invalid content type":        {ContentType: "json"},
+		"invalid memory":              {Memory: "8 pages"},
+	}
+	for name, c := range tcs {
+		t.Run(name, func(t *testing.T) {
+			if _, err := c.Inspects(); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestSize(t *testing.T) {
+	tcs := map[string]int64{
+		"512":                    512,
+		"512B":                   512,
+		"64KB":                   64_000,
+		"32MiB":                  32 << 20,
+		"1 GiB":                  1 << 30,
+		" 2MB ":                  2_000_000,
+		"0":                      0,
+		"1024Ki":                 -1,
+		"1.5MB":                  -1,
+		"-1":                     -1,
+		"MiB":                    -1,
+		"9223372036854775807GiB": -1,
+	}
+	for input, expected := range tcs {
+		t.Run(input, func(t *testing.T) {
+			got, err := size(input)
+			if expected == -1 {
+				if err == nil {
+					t.Errorf("expected an error, got %d", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("act: %v", err)
+			}
+			if got != expected {
+				t.Errorf("expected %d got %d", expected, got)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/pets\n"), 0o644); err != nil {
+		t.Fatalf("prep: %v", err)
+	}
+	sub := filepath.Join(root, "services", "pets")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatalf("prep: %v", err)
+	}
+	t.Chdir(sub)
+
+	path, err := Find()
+	if err != nil || path != "" {
+		t.Errorf("expected no config file, got %q and %v", path, err)
+	}
+
+	expected := filepath.Join(root, Filename)
+	if err := os.WriteFile(expected, []byte("conventions:\n  paths: snake\n"), 0o644); err != nil {
+		t.Fatalf("prep: %v", err)
+	}
+	path, err = Find()
+	if err != nil || path != expected {
+		t.Errorf("expected %q, got %q and %v", expected, path, err)
+	}
+
+	conv, path, err := Default("")
+	if err != nil || path != expected || conv.Paths != "snake" {
+		t.Errorf("expected the conventions of the found file, got %v from %q and %v", conv, path, err)
+	}
+}
+
+func TestDefault_path(t *testing.T) {
+	t.Chdir(t.TempDir())
+	expected := write(t, "other.yml", "conventions:\n  paths: kebab\n")
+	conv, path, err := Default(expected)
+	if err != nil || path != expected || conv.Paths != "kebab" {
+		t.Errorf("expected the conventions of the given file, got %v from %q and %v", conv, path, err)
+	}
+
+	conv, path, err = Default("")
+	if err != nil || path != "" || conv.Paths != "" {
+		t.Errorf("expected the defaults outside modules, got %v from %q and %v", conv, path, err)
+	}
+}
@@ -0,0 +1,180 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.ufukty.com/gohandlers/cmd/gohandlers/commands/version"
+	"go.ufukty.com/gohandlers/pkg/inspects"
+)
+
+var Formats = []string{"json", "sarif"}
+
+type jsonDiagnostic struct {
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Handler  string `json:"handler,omitempty"`
+	Message  string `json:"message"`
+}
+
+func toJson(ds []inspects.Diagnostic) []jsonDiagnostic {
+	jds := []jsonDiagnostic{}
+	for _, d := range ds {
+		jds = append(jds, jsonDiagnostic{
+			File:     relative(d.Pos.Filename),
+			Line:     d.Pos.Line,
+			Column:   d.Pos.Column,
+			Severity: d.Severity.String(),
+			Code:     d.Code,
+			Handler:  d.Handler,
+			Message:  d.Message,
+		})
+	}
+	return jds
+}
+
+// only the subset of SARIF 2.1.0 code scanning services make use of
+type (
+	sarifLog struct {
+		Version string     `json:"version"`
+		Schema  string     `json:"$schema"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		Version        string      `json:"version,omitempty"`
+		InformationUri string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+	sarifRule struct {
+		Id string `json:"id"`
+	}
+	sarifResult struct {
+		RuleId    string          `json:"ruleId"`
+		Level     string          `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           *sarifRegion          `json:"region,omitempty"`
+	}
+	sarifArtifactLocation struct {
+		Uri string `json:"uri"`
+	}
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn,omitempty"`
+	}
+)
+
+var levels = map[inspects.Severity]string{
+	inspects.Notice:  "note",
+	inspects.Warning: "warning",
+	inspects.Error:   "error",
+}
+
+func toSarif(ds []inspects.Diagnostic) sarifLog {
+	rules := []sarifRule{}
+	results := []sarifResult{}
+	for _, d := range ds {
+		if !slices.Contains(rules, sarifRule{d.Code}) {
+			rules = append(rules, sarifRule{d.Code})
+		}
+		msg := d.Message
+		if d.Handler != "" {
+			msg = fmt.Sprintf("%s: %s", d.Handler, d.Message)
+		}
+		l := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{Uri: filepath.ToSlash(relative(d.Pos.Filename))}}
+		if d.Pos.Line > 0 {
+			l.Region = &sarifRegion{StartLine: d.Pos.Line, StartColumn: d.Pos.Column}
+		}
+		results = append(results, sarifResult{
+			RuleId:    d.Code,
+			Level:     levels[d.Severity],
+			Message:   sarifMessage{Text: msg},
+			Locations: []sarifLocation{{PhysicalLocation: l}},
+		})
+	}
+	slices.SortFunc(rules, func(a, b sarifRule) int { return strings.Compare(a.Id, b.Id) })
+	return sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "gohandlers",
+				Version:        version.Version,
+				InformationUri: "https://github.com/ufukty/gohandlers",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+}
+
+// relative makes the paths relative to working directory, as
+// the code scanning services expect paths relative to the repository root
+func relative(path string) string {
+	if !filepath.IsAbs(path) {
+		return path
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
+// Encode writes the diagnostics in one of [Formats]
+func Encode(w io.Writer, format string, ds []inspects.Diagnostic) error {
+	var v any
+	switch format {
+	case "json":
+		v = toJson(ds)
+	case "sarif":
+		v = toSarif(ds)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(v)
+}
+
+// WriteFile encodes the diagnostics into the file at path
+func WriteFile(path, format string, ds []inspects.Diagnostic) error {
+	fh, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating diagnostics file: %w", err)
+	}
+	defer fh.Close()
+	if err := Encode(fh, format, ds); err != nil {
+		return fmt.Errorf("writing diagnostics file: %w", err)
+	}
+	return nil
+}
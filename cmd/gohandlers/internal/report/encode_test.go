@@ -0,0 +1,126 @@
+package report
+
+import (
+	"bytes"
+	"flag"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"go.ufukty.com/gohandlers/pkg/inspects"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func diagnostics(t *testing.T) []inspects.Diagnostic {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("prep: %v", err)
+	}
+	return []inspects.Diagnostic{
+		{
+			Pos:      token.Position{Filename: filepath.Join(wd, "pets", "pets.go"), Line: 12, Column: 16},
+			Severity: inspects.Error,
+			Code:     inspects.CodeRouteConflict,
+			Handler:  "Visit",
+			Message:  "GET /pets/{id} duplicates the route of Pets.Get",
+		},
+		{
+			Pos:      token.Position{Filename: filepath.Join(wd, "pets", "types.go"), Line: 3},
+			Severity: inspects.Warning,
+			Code:     inspects.CodeBindingUnmentioned,
+			Handler:  "Get",
+			Message:  "skipping GetRequest as it is not mentioned in the handler body",
+		},
+		{
+			Pos:      token.Position{Filename: "pets"},
+			Severity: inspects.Notice,
+			Code:     inspects.CodeTypeError,
+			Message:  "undefined: Missing",
+		},
+		{
+			Pos:      token.Position{Filename: filepath.Join(wd, "pets", "pets.go"), Line: 20, Column: 16},
+			Severity: inspects.Error,
+			Code:     inspects.CodeRouteConflict,
+			Handler:  "Feed",
+			Message:  "GET /pets/{id} duplicates the route of Pets.Get",
+		},
+	}
+}
+
+func TestEncode(t *testing.T) {
+	for _, format := range Formats {
+		t.Run(format, func(t *testing.T) {
+			buf := bytes.NewBuffer(nil)
+			if err := Encode(buf, format, diagnostics(t)); err != nil {
+				t.Fatalf("act: %v", err)
+			}
+			golden := filepath.Join("testdata", "diagnostics."+format)
+			if *update {
+				if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+					t.Fatalf("updating %s: %v", golden, err)
+				}
+			}
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("prep: %v", err)
+			}
+			if got := buf.String(); got != string(expected) {
+				t.Errorf("expected %s got %s", expected, got)
+			}
+		})
+	}
+}
+
+func TestEncode_unknownFormat(t *testing.T) {
+	if err := Encode(bytes.NewBuffer(nil), "xml", nil); err == nil {
+		t.Errorf("expected an error for unknown format")
+	}
+}
+
+func TestToSarif_locations(t *testing.T) {
+	l := toSarif(diagnostics(t))
+	if len(l.Runs) != 1 {
+		t.Fatalf("expected 1 run got %d", len(l.Runs))
+	}
+	rules := []string{}
+	for _, r := range l.Runs[0].Tool.Driver.Rules {
+		rules = append(rules, r.Id)
+	}
+	expected := []string{inspects.CodeBindingUnmentioned, inspects.CodeRouteConflict, inspects.CodeTypeError}
+	if !slices.Equal(rules, expected) {
+		t.Errorf("expected rules %v got %v", expected, rules)
+	}
+
+	first := l.Runs[0].Results[0].Locations[0].PhysicalLocation
+	if first.ArtifactLocation.Uri != "pets/pets.go" {
+		t.Errorf("expected the uri relative to working directory, got %q", first.ArtifactLocation.Uri)
+	}
+	if first.Region == nil || first.Region.StartLine != 12 || first.Region.StartColumn != 16 {
+		t.Errorf("expected the region at 12:16, got %#v", first.Region)
+	}
+	if r := l.Runs[0].Results[2].Locations[0].PhysicalLocation.Region; r != nil {
+		t.Errorf("expected no region for the diagnostic without line, got %#v", r)
+	}
+}
+
+func TestRelative(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("prep: %v", err)
+	}
+	tcs := map[string]string{
+		filepath.Join(wd, "pets", "pets.go"):       filepath.Join("pets", "pets.go"),
+		filepath.Join(filepath.Dir(wd), "main.go"): filepath.Join("..", "main.go"),
+		filepath.Join("pets", "pets.go"):           filepath.Join("pets", "pets.go"),
+		"":                                         "",
+	}
+	for input, expected := range tcs {
+		if got := relative(input); got != expected {
+			t.Errorf("relative(%q): expected %q got %q", input, expected, got)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package report
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+
+	"go.ufukty.com/gohandlers/pkg/inspects"
+)
+
+var colors = map[inspects.Severity]string{
+	inspects.Notice:  "\033[34m",
+	inspects.Warning: "\033[33m",
+	inspects.Error:   "\033[31m",
+}
+
+func colored(s inspects.Severity) string {
+	return colors[s] + s.String() + "\033[0m"
+}
+
+// Diagnostics prints the diagnostics in the "file:line:col: severity: handler: message"
+// format. Notices are only printed in verbose mode.
+func Diagnostics(w io.Writer, ds []inspects.Diagnostic, verbose bool) {
+	for _, d := range ds {
+		if d.Severity == inspects.Notice && !verbose {
+			continue
+		}
+		if d.Handler == "" {
+			fmt.Fprintf(w, "%s: %s: %s\n", d.Pos, colored(d.Severity), d.Message)
+		} else {
+			fmt.Fprintf(w, "%s: %s: %s: %s\n", d.Pos, colored(d.Severity), d.Handler, d.Message)
+		}
+	}
+}
+
+// Handlers prints the method and path assigned to each handler
+func Handlers(w io.Writer, infoss map[inspects.Receiver]map[string]inspects.Info) {
+	recvs := slices.SortedFunc(maps.Keys(infoss), func(a, b inspects.Receiver) int {
+		return cmp.Compare(a.Type, b.Type)
+	})
+	for _, recv := range recvs {
+		for _, h := range slices.Sorted(maps.Keys(infoss[recv])) {
+			i := infoss[recv][h]
+			fmt.Fprintf(w, "adding %s %s for %s\n", i.Method, i.Path, h)
+		}
+	}
+}
+
+// Failing returns the number of diagnostics should fail the command. Errors
+// always fail it, warnings only when werror is set.
+func Failing(ds []inspects.Diagnostic, werror bool) int {
+	n := 0
+	for _, d := range ds {
+		if d.Severity == inspects.Error || (werror && d.Severity == inspects.Warning) {
+			n++
+		}
+	}
+	return n
+}
+
+// Fail returns the error for the n failing diagnostics, or nil
+func Fail(n int, werror bool) error {
+	switch {
+	case n == 0:
+		return nil
+	case werror:
+		return fmt.Errorf("found %d warnings or errors", n)
+	}
+	return fmt.Errorf("found %d errors", n)
+}
@@ -0,0 +1,61 @@
+package targets
+
+import (
+	"cmp"
+	"fmt"
+	"path/filepath"
+
+	"go.ufukty.com/gohandlers/pkg/inspects"
+	"golang.org/x/tools/go/packages"
+)
+
+type Target struct {
+	inspects.PackageDir
+	Out string
+}
+
+func (t Target) String() string {
+	return cmp.Or(t.Path, t.Dir)
+}
+
+// List resolves the output file of each package matched by the dir. When
+// the dir is a pattern or relative is set, the out is relative to the
+// package directory.
+func List(dir, out string, relative bool) ([]Target, error) {
+	matched := inspects.IsPattern(dir)
+	if matched && filepath.IsAbs(out) {
+		return nil, fmt.Errorf("output path needs to be relative when the dir is a pattern")
+	}
+
+	dirs, err := inspects.Match(dir)
+	if err != nil {
+		return nil, fmt.Errorf("matching packages: %w", err)
+	}
+
+	ts := []Target{}
+	outs := map[string]Target{}
+	for _, d := range dirs {
+		t := Target{PackageDir: d, Out: out}
+		if matched || relative {
+			t.Out = filepath.Join(d.Dir, out)
+		}
+		if prev, ok := outs[t.Out]; ok {
+			return nil, fmt.Errorf("both %s and %s would be generated into %s", prev, t, t.Out)
+		}
+		outs[t.Out] = t
+		ts = append(ts, t)
+	}
+	return ts, nil
+}
+
+// ImportPath resolves the import path of the package in dir
+func ImportPath(dir string) (string, error) {
+	ps, err := packages.Load(&packages.Config{Mode: packages.NeedName, Dir: dir}, ".")
+	if err != nil {
+		return "", fmt.Errorf("loading package: %w", err)
+	}
+	if len(ps) != 1 || ps[0].PkgPath == "" {
+		return "", fmt.Errorf("resolving the import path of %s", dir)
+	}
+	return ps[0].PkgPath, nil
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"go.ufukty.com/gohandlers/cmd/gohandlers/commands/client"
+	"go.ufukty.com/gohandlers/cmd/gohandlers/commands/generate"
 	"go.ufukty.com/gohandlers/cmd/gohandlers/commands/helpers"
 	"go.ufukty.com/gohandlers/cmd/gohandlers/commands/version"
 	"go.ufukty.com/gohandlers/cmd/gohandlers/commands/yaml"
@@ -19,10 +20,11 @@ func listcmds(commands map[string]func() error) string {
 
 func Main() error {
 	commands := map[string]func() error{
-		"client":  client.Main,
-		"helpers": helpers.Main,
-		"version": version.Main,
-		"yaml":    yaml.Main,
+		"client":   client.Main,
+		"generate": generate.Main,
+		"helpers":  helpers.Main,
+		"version":  version.Main,
+		"yaml":     yaml.Main,
 	}
 
 	if len(os.Args) < 2 {
@@ -0,0 +1,7 @@
+package gohandlers
+
+import "errors"
+
+// ErrMissing is wrapped by the errors of generated Parse methods for the
+// fields tagged with the required option when the value is not sent
+var ErrMissing = errors.New("missing required value")
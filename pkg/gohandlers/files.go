@@ -0,0 +1,9 @@
+package gohandlers
+
+import "io"
+
+// File is a file the request builders send in multipart/form-data bodies
+type File struct {
+	Name    string // file name; the server sees it as [multipart.FileHeader.Filename]
+	Content io.Reader
+}
@@ -0,0 +1,151 @@
+package inspects
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// relationship of the sets of requests two patterns match, as [http.ServeMux]
+// decides which patterns can be registered together
+type relationship int
+
+const (
+	equivalent   relationship = iota // both match the same requests
+	moreGeneral                      // the first matches a superset of the second
+	moreSpecific                     // the first matches a subset of the second
+	disjoint                         // no request is matched by both
+	overlaps                         // some requests are matched by both, neither is more specific
+)
+
+func inverse(r relationship) relationship {
+	switch r {
+	case moreGeneral:
+		return moreSpecific
+	case moreSpecific:
+		return moreGeneral
+	}
+	return r
+}
+
+func combine(r1, r2 relationship) relationship {
+	switch r1 {
+	case equivalent:
+		return r2
+	case disjoint:
+		return disjoint
+	case overlaps:
+		if r2 == disjoint {
+			return disjoint
+		}
+		return overlaps
+	}
+	switch r2 {
+	case equivalent:
+		return r1
+	case inverse(r1):
+		return overlaps
+	}
+	return r2
+}
+
+// compareMethods treats GET as it also matches HEAD requests
+func compareMethods(m1, m2 string) relationship {
+	switch {
+	case m1 == m2:
+		return equivalent
+	case m1 == http.MethodGet && m2 == http.MethodHead:
+		return moreGeneral
+	case m1 == http.MethodHead && m2 == http.MethodGet:
+		return moreSpecific
+	}
+	return disjoint
+}
+
+func compareSegments(s1, s2 Segment) relationship {
+	switch {
+	case s1.Multi && s2.Multi:
+		return equivalent
+	case s1.Multi:
+		return moreGeneral
+	case s2.Multi:
+		return moreSpecific
+	case s1.Wild && s2.Wild:
+		return equivalent
+	case s1.Wild:
+		if s2.S == "/" {
+			return disjoint // a single wildcard doesn't match the trailing slash
+		}
+		return moreGeneral
+	case s2.Wild:
+		if s1.S == "/" {
+			return disjoint
+		}
+		return moreSpecific
+	case s1.S == s2.S:
+		return equivalent
+	}
+	return disjoint
+}
+
+func (p1 *Pattern) comparePaths(p2 *Pattern) relationship {
+	segs1, segs2 := p1.Segments, p2.Segments
+	if len(segs1) != len(segs2) && !p1.Subtree() && !p2.Subtree() {
+		return disjoint
+	}
+	rel := equivalent
+	for ; len(segs1) > 0 && len(segs2) > 0; segs1, segs2 = segs1[1:], segs2[1:] {
+		if rel = combine(rel, compareSegments(segs1[0], segs2[0])); rel == disjoint {
+			return rel
+		}
+	}
+	switch {
+	case len(segs1) == 0 && len(segs2) == 0:
+		return rel
+	case len(segs1) < len(segs2) && p1.Subtree():
+		return combine(rel, moreGeneral)
+	case len(segs2) < len(segs1) && p2.Subtree():
+		return combine(rel, moreSpecific)
+	}
+	return disjoint
+}
+
+// compare returns the relationship of two routes. Patterns with
+// different hosts never conflict, as the one with a host wins when
+// the other doesn't have one.
+func compare(m1 string, p1 *Pattern, m2 string, p2 *Pattern) relationship {
+	if p1.Host != p2.Host {
+		return disjoint
+	}
+	mrel := compareMethods(m1, m2)
+	if mrel == disjoint {
+		return disjoint
+	}
+	return combine(mrel, p1.comparePaths(p2))
+}
+
+func (e entry) String() string {
+	if e.recv.Type == "" {
+		return e.h.Name.Name
+	}
+	return fmt.Sprintf("%s.%s", e.recv.Type, e.h.Name.Name)
+}
+
+// conflicts reports the routes [http.ServeMux] would panic at registering
+// after the routes registered earlier by listers
+func conflicts(routes []entry) []Diagnostic {
+	sortEntries(routes)
+	ds := []Diagnostic{}
+	for j, r2 := range routes {
+		for _, r1 := range routes[:j] {
+			switch compare(r1.info.Method, r1.info.Pattern, r2.info.Method, r2.info.Pattern) {
+			case equivalent:
+				ds = append(ds, diagnose(r2.pos, r2.h, Error, CodeRouteConflict, "%s %s duplicates the route of %s",
+					r2.info.Method, r2.info.Path, r1))
+			case overlaps:
+				ds = append(ds, diagnose(r2.pos, r2.h, Error, CodeRouteConflict, "%s %s overlaps with %s %s of %s, as neither is more specific",
+					r2.info.Method, r2.info.Path, r1.info.Method, r1.info.Path, r1))
+			}
+		}
+	}
+	return ds
+}
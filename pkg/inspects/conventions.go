@@ -0,0 +1,121 @@
+package inspects
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"mime"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Conventions are the rules for deriving the methods, paths and content
+// types that are not specified in doc comments. Zero values are defaults.
+type Conventions struct {
+	Verbs       map[string]string // handler name prefixes to methods, eg. "Create" to "POST"
+	Paths       string            // style of the paths derived from handler names; one of [PathStyles]
+	ContentType string            // of json bodies
+	MaxMemory   int64             // of multipart bodies kept in memory while parsing; [DefaultMaxMemory] when zero
+}
+
+// DefaultMaxMemory is the default of [Conventions.MaxMemory], same as
+// [http.Request.FormFile] uses
+const DefaultMaxMemory = 32 << 20
+
+// PathStyles are the styles paths can be derived from handler names with.
+// For the Pets.GetPhoto handler, in order:
+//
+//	/get-photo
+//	/get_photo
+//	/pets/photo
+//	/photo
+var PathStyles = []string{"kebab", "snake", "resource", "verb-stripped"}
+
+var verb = regexp.MustCompile(`^[A-Z][a-z]+$`)
+
+// Validate checks the overrides
+func (c Conventions) Validate() error {
+	for prefix, method := range c.Verbs {
+		if !verb.MatchString(prefix) {
+			return fmt.Errorf("verb prefix %q needs to be a capitalized word", prefix)
+		}
+		if !slices.Contains(methods, method) {
+			return fmt.Errorf("unknown method %s for the verb prefix %s", method, prefix)
+		}
+	}
+	if c.Paths != "" && !slices.Contains(PathStyles, c.Paths) {
+		return fmt.Errorf("unknown path style %q", c.Paths)
+	}
+	if c.MaxMemory < 0 {
+		return fmt.Errorf("negative multipart memory limit %d", c.MaxMemory)
+	}
+	if c.ContentType != "" {
+		mt, _, err := mime.ParseMediaType(c.ContentType)
+		if err != nil {
+			return fmt.Errorf("content type: %w", err)
+		}
+		if !strings.Contains(mt, "/") {
+			return fmt.Errorf("content type %q is not in the type/subtype form", c.ContentType)
+		}
+	}
+	return nil
+}
+
+// DefaultVerbs returns a copy of the default handler name prefixes
+func DefaultVerbs() map[string]string {
+	return maps.Clone(methodMap)
+}
+
+func (c Conventions) verbs() map[string]string {
+	if c.Verbs == nil {
+		return methodMap
+	}
+	return c.Verbs
+}
+
+// stripVerb returns the rest of handler name after the verb prefix
+func (c Conventions) stripVerb(name string) string {
+	matches := titles.FindStringSubmatch(name)
+	if len(matches) > 1 && strings.HasPrefix(name, matches[1]) && has(c.verbs(), matches[1]) {
+		return strings.TrimPrefix(name, matches[1])
+	}
+	return name
+}
+
+// derive returns the path for the handler name in the style, without the
+// route parameters. The resource style uses the receiver type as the
+// resource, unless the receiver type has a prefix which replaces it. The
+// path can be empty for the resource style.
+func (c Conventions) derive(name, recvt string, prefixed bool) string {
+	switch c.Paths {
+	case "snake":
+		return "/" + snake(name)
+	case "verb-stripped":
+		return "/" + kebab(cmp.Or(c.stripVerb(name), name))
+	case "resource":
+		if recvt == "" {
+			return "/" + kebab(cmp.Or(c.stripVerb(name), name))
+		}
+		path := ""
+		if !prefixed {
+			path += "/" + kebab(recvt)
+		}
+		if rest := c.stripVerb(name); rest != "" && rest != recvt {
+			path += "/" + kebab(rest)
+		}
+		return path
+	}
+	return "/" + kebab(name)
+}
+
+// body replaces the content type of json bodies and the memory limit of
+// multipart bodies
+func (c Conventions) body(bti *BindingTypeInfo) {
+	if bti != nil && c.ContentType != "" && len(bti.Params.Json) > 0 {
+		bti.ContentType = c.ContentType
+	}
+	if bti != nil && c.MaxMemory != 0 && len(bti.Params.File) > 0 {
+		bti.MaxMemory = c.MaxMemory
+	}
+}
@@ -0,0 +1,93 @@
+package inspects
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+type Severity int
+
+const (
+	Notice Severity = iota
+	Warning
+	Error
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Notice:
+		return "notice"
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// Codes are stable identifiers of diagnostics for tools to filter on
+const (
+	CodeIgnored            = "ignored"
+	CodeDirective          = "directive"
+	CodeTypeError          = "type-error"
+	CodeBindingUnmentioned = "binding-unmentioned"
+	CodeBindingUnresolved  = "binding-unresolved"
+	CodeBindingImported    = "binding-imported"
+	CodeBindingShared      = "binding-shared"
+	CodeFactoryVariadic    = "factory-variadic"
+	CodeHandlerUndirected  = "handler-undirected"
+	CodeFieldMethods       = "field-methods"
+	CodeFieldType          = "field-type"
+	CodeMethodImplicit     = "method-implicit"
+	CodeMethodNameConflict = "method-name-conflict"
+	CodeMethodBodyConflict = "method-body-conflict"
+	CodePathParamsAppended = "path-params-appended"
+	CodePathInvalid        = "path-invalid"
+	CodeRouteConflict      = "route-conflict"
+)
+
+type Diagnostic struct {
+	Pos      token.Position
+	Severity Severity
+	Code     string
+	Handler  string // empty for diagnostics not specific to a handler
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	if d.Handler == "" {
+		return fmt.Sprintf("%s: %s: %s", d.Pos, d.Severity, d.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s: %s", d.Pos, d.Severity, d.Handler, d.Message)
+}
+
+func diagnose(pos token.Position, h *ast.FuncDecl, s Severity, code, format string, a ...any) Diagnostic {
+	return Diagnostic{
+		Pos:      pos,
+		Severity: s,
+		Code:     code,
+		Handler:  h.Name.Name,
+		Message:  fmt.Sprintf(format, a...),
+	}
+}
+
+// position parses the "file:line:col" formatted positions
+func position(s string) token.Position {
+	pos := token.Position{Filename: s}
+	ss := strings.Split(s, ":")
+	if len(ss) < 3 {
+		return pos
+	}
+	line, err := strconv.Atoi(ss[len(ss)-2])
+	if err != nil {
+		return pos
+	}
+	col, err := strconv.Atoi(ss[len(ss)-1])
+	if err != nil {
+		return pos
+	}
+	return token.Position{Filename: strings.Join(ss[:len(ss)-2], ":"), Line: line, Column: col}
+}
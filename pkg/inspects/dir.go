@@ -6,18 +6,24 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"go/types"
+	"io/fs"
 	"iter"
 	"maps"
+	"mime"
 	"net/http"
-	"os"
+	"net/textproto"
+	pathpkg "path"
 	"path/filepath"
 	"reflect"
 	"regexp"
 	"slices"
+	"strconv"
 	"strings"
 	"unicode"
 
 	"go.ufukty.com/gohandlers/pkg/inspects/join"
+	"golang.org/x/tools/go/packages"
 )
 
 func first[E any](i iter.Seq[E]) (e E) {
@@ -28,61 +34,254 @@ func first[E any](i iter.Seq[E]) (e E) {
 	return
 }
 
-var (
-	NOTICE  = "\033[34m" + "notice" + "\033[0m"
-	WARNING = "\033[33m" + "warning" + "\033[0m"
-	ERROR   = "\033[31m" + "error" + "\033[0m"
-)
+// importName returns the name the file refers to the package at path with,
+// "." for dot imports and empty string when the package is not imported
+func importName(f *ast.File, path string) string {
+	for _, is := range f.Imports {
+		if p, err := strconv.Unquote(is.Path.Value); err == nil && p == path {
+			if is.Name != nil {
+				return is.Name.Name
+			}
+			return pathpkg.Base(path)
+		}
+	}
+	return ""
+}
 
-func linearize(n ast.Node) []string {
-	literals := []string{}
-	ast.Inspect(n, func(n ast.Node) bool {
-		if n == nil {
-			return true
+// qualifies checks if the expression refers to the name declared in the
+// package which is imported as pkg
+func qualifies(e ast.Expr, pkg, name string) bool {
+	switch e := e.(type) {
+	case *ast.SelectorExpr:
+		x, ok := e.X.(*ast.Ident)
+		return ok && x.Name == pkg && e.Sel.Name == name
+	case *ast.Ident:
+		return pkg == "." && e.Name == name
+	}
+	return false
+}
+
+// fieldTypes lists the types of fields by repeating the type for each name
+func fieldTypes(fl *ast.FieldList) []ast.Expr {
+	ts := []ast.Expr{}
+	if fl == nil {
+		return ts
+	}
+	for _, f := range fl.List {
+		for range max(1, len(f.Names)) {
+			ts = append(ts, f.Type)
 		}
-		switch x := n.(type) {
-		case *ast.BasicLit:
-			literals = append(literals, x.Value)
-		case *ast.Ident:
-			literals = append(literals, x.Name)
-		default:
-			literals = append(literals, fmt.Sprintf("%T", x))
+	}
+	return ts
+}
+
+// isHandler checks if the function has the [http.HandlerFunc] signature,
+// regardless of parameter names and the name net/http is imported with
+func isHandler(f *ast.File, fd *ast.FuncDecl) bool {
+	http := importName(f, "net/http")
+	if http == "" || http == "_" || len(fieldTypes(fd.Type.Results)) > 0 {
+		return false
+	}
+	ps := fieldTypes(fd.Type.Params)
+	if len(ps) != 2 {
+		return false
+	}
+	r, ok := ps[1].(*ast.StarExpr)
+	return ok && qualifies(ps[0], http, "ResponseWriter") && qualifies(r.X, http, "Request")
+}
+
+// factoryResult returns the name of the net/http type the function returns
+// when it returns either [http.HandlerFunc] or [http.Handler]. Functions
+// taking either of them are middlewares rather than factories.
+func factoryResult(f *ast.File, fd *ast.FuncDecl) string {
+	http := importName(f, "net/http")
+	if http == "" || http == "_" {
+		return ""
+	}
+	rs := fieldTypes(fd.Type.Results)
+	if len(rs) != 1 {
+		return ""
+	}
+	for _, p := range fieldTypes(fd.Type.Params) {
+		if e, ok := p.(*ast.Ellipsis); ok {
+			p = e.Elt
 		}
-		return true
-	})
-	return literals
+		if qualifies(p, http, "HandlerFunc") || qualifies(p, http, "Handler") {
+			return ""
+		}
+	}
+	for _, t := range []string{"HandlerFunc", "Handler"} {
+		if qualifies(rs[0], http, t) {
+			return t
+		}
+	}
+	return ""
 }
 
-var handler = linearize(&ast.FieldList{
-	List: []*ast.Field{
-		{
-			Names: []*ast.Ident{{Name: "w"}},
-			Type:  &ast.SelectorExpr{X: &ast.Ident{Name: "http"}, Sel: &ast.Ident{Name: "ResponseWriter"}},
-		},
-		{
-			Names: []*ast.Ident{{Name: "r"}},
-			Type:  &ast.StarExpr{X: &ast.SelectorExpr{X: &ast.Ident{Name: "http"}, Sel: &ast.Ident{Name: "Request"}}},
-		},
-	},
-})
+type handlerKind int
+
+func (k handlerKind) String() string {
+	switch k {
+	case typeHandler:
+		return "handler type"
+	case factoryHandler:
+		return "factory"
+	}
+	return "handler"
+}
+
+const (
+	funcHandler    handlerKind = iota // func (a *Api) Get(w http.ResponseWriter, r *http.Request)
+	typeHandler                       // func (u *Upload) ServeHTTP(w http.ResponseWriter, r *http.Request)
+	factoryHandler                    // func (a *Api) Upload(limit int) http.HandlerFunc
+)
+
+// handler is a declaration that serves or produces a handler. The
+// declarations of handler types are named after their receiver types.
+type handler struct {
+	*ast.FuncDecl
+	ident   *ast.Ident // the declared name, as the handler might be renamed
+	kind    handlerKind
+	returns string // "HandlerFunc" or "Handler" for factories
+}
+
+func newHandler(fd *ast.FuncDecl, kind handlerKind, returns string) handler {
+	h := handler{FuncDecl: fd, ident: fd.Name, kind: kind, returns: returns}
+	if kind == typeHandler {
+		if recvt, err := receiverType(fd); err == nil {
+			h = h.rename(recvt)
+		}
+	}
+	return h
+}
 
-func isHandler(fd *ast.FuncDecl) bool {
-	return slices.Compare(linearize(fd.Type.Params), handler) == 0
+func (h handler) rename(name string) handler {
+	fd := *h.FuncDecl
+	fd.Name = &ast.Ident{NamePos: h.ident.NamePos, Name: name}
+	h.FuncDecl = &fd
+	return h
 }
 
-func findHandlers(f *ast.File) []*ast.FuncDecl {
-	hs := []*ast.FuncDecl{}
+func kindOf(fd *ast.FuncDecl) handlerKind {
+	if fd.Recv != nil && fd.Name.Name == "ServeHTTP" {
+		return typeHandler
+	}
+	return funcHandler
+}
+
+// findHandlers uses the resolved signatures when the type information is
+// available, so parameters declared with aliases are also recognized
+func (src source) findHandlers(f *ast.File) []handler {
+	hs := []handler{}
 	for _, d := range f.Decls {
-		if fd, ok := d.(*ast.FuncDecl); ok && isHandler(fd) {
-			hs = append(hs, fd)
+		fd, ok := d.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		if fn, ok := src.defined(fd); ok {
+			if isHandlerSignature(fn.Signature()) {
+				hs = append(hs, newHandler(fd, kindOf(fd), ""))
+			} else if r := factorySignature(fn.Signature()); r != "" {
+				hs = append(hs, newHandler(fd, factoryHandler, r))
+			}
+		} else if isHandler(f, fd) {
+			hs = append(hs, newHandler(fd, kindOf(fd), ""))
+		} else if r := factoryResult(f, fd); r != "" {
+			hs = append(hs, newHandler(fd, factoryHandler, r))
 		}
 	}
 	return hs
 }
 
+// receivers returns the receiver types with handlers of the
+// [http.HandlerFunc] signature. Factories and handler types are only listed
+// along with them, unless they have directives, as functions returning
+// [http.Handler] and types implementing it are often routers and
+// middlewares rather than handlers.
+func receivers(hss map[*ast.File][]handler) map[string]bool {
+	recvs := map[string]bool{}
+	for _, hs := range hss {
+		for _, h := range hs {
+			if h.kind != funcHandler {
+				continue
+			}
+			if recvt, err := receiverType(h.FuncDecl); err == nil {
+				recvs[recvt] = true
+			}
+		}
+	}
+	return recvs
+}
+
+func lowerFirst(s string) string {
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+func upperFirst(s string) string {
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// variadic checks if the last parameter of the factory is variadic, which
+// can't be placed among the parameters of listers
+func (h handler) variadic() bool {
+	ps := h.Type.Params.List
+	if h.kind != factoryHandler || len(ps) == 0 {
+		return false
+	}
+	_, ok := ps[len(ps)-1].Type.(*ast.Ellipsis)
+	return ok
+}
+
+// args returns the parameters of factories, named after the handler, that
+// the listers need to take to call the factories
+func (h handler) args() []*ast.Field {
+	if h.kind != factoryHandler {
+		return nil
+	}
+	fs := []*ast.Field{}
+	i := 0
+	for _, f := range h.Type.Params.List {
+		for j := range max(1, len(f.Names)) {
+			n := fmt.Sprintf("%s%d", lowerFirst(h.Name.Name), i)
+			if j < len(f.Names) && f.Names[j].Name != "_" {
+				n = lowerFirst(h.Name.Name) + upperFirst(f.Names[j].Name)
+			}
+			fs = append(fs, &ast.Field{Names: []*ast.Ident{ast.NewIdent(n)}, Type: f.Type})
+			i++
+		}
+	}
+	return fs
+}
+
+// imports returns the imports of file that the types of args refer to
+func imports(f *ast.File, args []*ast.Field) []*ast.ImportSpec {
+	used := map[string]bool{}
+	for _, a := range args {
+		ast.Inspect(a.Type, func(n ast.Node) bool {
+			if se, ok := n.(*ast.SelectorExpr); ok {
+				if x, ok := se.X.(*ast.Ident); ok {
+					used[x.Name] = true
+				}
+			}
+			return true
+		})
+	}
+	iss := []*ast.ImportSpec{}
+	for _, is := range f.Imports {
+		p, err := strconv.Unquote(is.Path.Value)
+		if err != nil {
+			continue
+		}
+		if used[importName(f, p)] {
+			iss = append(iss, is)
+		}
+	}
+	return iss
+}
+
 type BindingTypeParameterSources struct {
-	Route, Query map[string]string // Header
-	Json, Form   map[string]string // Body
+	Route, Query, Header, Cookie map[string]string // Header
+	Json, Form, File             map[string]string // Body
 }
 
 type BindingTypeInfo struct {
@@ -90,42 +289,247 @@ type BindingTypeInfo struct {
 	ContainsBody bool
 	Empty        bool
 	ContentType  string
-	Params       BindingTypeParameterSources // param -> fieldname
+	MaxMemory    int64                       // bytes of multipart bodies kept in memory while parsing
+	Params       BindingTypeParameterSources // param -> field path (eg. "Paging.Limit" for promoted fields)
+	Declared     []string                    // tagged field paths in the declaration order
+	Status       string                      // path of the field tagged with status; response bindings only
+	Body         string                      // path of the field tagged with body, which carries the raw body; request bindings only
+	Arrays       map[string]string           // query param -> encoding of the values of fields bound to all values of the param; one of [ArrayEncodings]
+	Objects      map[string]bool             // query params of fields bound to the "param[key]" params with the deep option
+	Required     map[string]bool             // field paths of the params with the required option
+	Defaults     map[string]string           // field path -> value the params without values default to
+
+	// the json fields the views of bodies are declared with, so the fields
+	// bound to other sources don't end up in bodies
+	JsonTypes map[string]ast.Expr // field path -> type, as the package refers to it
+	JsonTags  map[string]string   // field path -> value of the json tag
+	Imports   []*ast.ImportSpec   // the imports the json types refer to
+
+	// only available when the package is inspected with type information
+	Type   types.Type
+	Fields map[string]types.Type // field path -> type
+
+	// set for the types declared in other packages, which can only be
+	// attached with directives and don't get their methods generated
+	Package     string // import path
+	PackageName string
+}
+
+// InOrder returns the params in the declaration order of their fields
+func (bti *BindingTypeInfo) InOrder(params map[string]string) []string {
+	ps := slices.Sorted(maps.Keys(params))
+	slices.SortStableFunc(ps, func(a, b string) int {
+		return cmp.Compare(slices.Index(bti.Declared, params[a]), slices.Index(bti.Declared, params[b]))
+	})
+	return ps
 }
 
-func bti(rqtn string, ts *ast.TypeSpec) (*BindingTypeInfo, error) {
-	bti := &BindingTypeInfo{
-		Typename: rqtn,
+// Viewed reports if the binding type has json fields along with fields bound
+// to other sources, which encoding/json would read from and write into the
+// body when the binding type is encoded as a whole. The body is encoded and
+// decoded through a view of the json fields instead.
+func (bti *BindingTypeInfo) Viewed() bool {
+	if len(bti.Params.Json) == 0 {
+		return false
+	}
+	for _, fp := range bti.Declared {
+		if _, ok := bti.JsonTypes[fp]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Local reports if the binding type is declared in the inspected package
+func (bti *BindingTypeInfo) Local() bool {
+	return bti != nil && bti.Package == ""
+}
+
+func newBindingTypeInfo(tn string) *BindingTypeInfo {
+	return &BindingTypeInfo{
+		Typename: tn,
 		Params: BindingTypeParameterSources{
-			Route: map[string]string{},
-			Query: map[string]string{},
-			Json:  map[string]string{},
-			Form:  map[string]string{},
+			Route:  map[string]string{},
+			Query:  map[string]string{},
+			Header: map[string]string{},
+			Cookie: map[string]string{},
+			Json:   map[string]string{},
+			Form:   map[string]string{},
+			File:   map[string]string{},
 		},
+		Arrays:    map[string]string{},
+		Objects:   map[string]bool{},
+		Required:  map[string]bool{},
+		Defaults:  map[string]string{},
+		JsonTypes: map[string]ast.Expr{},
+		JsonTags:  map[string]string{},
 	}
+}
 
-	if st, ok := ts.Type.(*ast.StructType); ok {
-		for _, f := range st.Fields.List {
-			if f.Tag != nil {
-				st := reflect.StructTag(strings.Trim(f.Tag.Value, "`"))
-				if v, ok := st.Lookup("route"); ok {
-					bti.Params.Route[v] = f.Names[0].Name
-				}
-				if v, ok := st.Lookup("query"); ok {
-					bti.Params.Query[v] = f.Names[0].Name
-				}
-				if v, ok := st.Lookup("json"); ok {
-					bti.Params.Json[v] = f.Names[0].Name
-				}
-				if v, ok := st.Lookup("form"); ok {
-					bti.Params.Form[v] = f.Names[0].Name
-				}
+var sources = []string{"route", "query", "header", "cookie", "json", "form", "file"}
+
+// markers are the tags of fields that are not bound to named parameters
+var markers = []string{"status", "body"}
+
+// ArrayEncodings are the options of query tags for binding the field to all
+// values of the parameter. Values are either sent in repeated parameters
+// (?tag=a&tag=b) or joined with commas (?tag=a,b).
+var ArrayEncodings = []string{"repeat", "comma"}
+
+// optional are the sources whose tags accept the required and default
+// options
+var optional = []string{"route", "query", "header", "form"}
+
+// split returns the name and the options in the tag value. The default
+// option takes the rest of the value, so defaults can contain commas.
+func split(v string) (string, []string) {
+	name, rest, found := strings.Cut(v, ",")
+	opts := []string{}
+	for found {
+		if strings.HasPrefix(rest, "default=") {
+			opts = append(opts, rest)
+			break
+		}
+		var opt string
+		opt, rest, found = strings.Cut(rest, ",")
+		opts = append(opts, opt)
+	}
+	return name, opts
+}
+
+func tagged(st reflect.StructTag) bool {
+	return slices.ContainsFunc(slices.Concat(sources, markers), func(src string) bool {
+		_, ok := st.Lookup(src)
+		return ok
+	})
+}
+
+// bound reports if the field is bound to a source or marked. Fields tagged
+// with json:"-" are left out of the body, so they aren't bound by the tag.
+func bound(st reflect.StructTag) bool {
+	return slices.ContainsFunc(slices.Concat(sources, markers), func(src string) bool {
+		v, ok := st.Lookup(src)
+		return ok && (src != "json" || v != "-")
+	})
+}
+
+// set adds the param to the map of source, unless the param is bound to
+// another field. json names are left to encoding/json
+func set(params map[string]string, src, param, fp string) error {
+	if prev, ok := params[param]; ok && src != "json" && prev != fp {
+		return fmt.Errorf("%s parameter %q is bound to both %s and %s", src, param, prev, fp)
+	}
+	params[param] = fp
+	return nil
+}
+
+// field adds the field to the parameters of tagged sources. fp is the field
+// path which contains the names of embedded structs for promoted fields.
+func (bti *BindingTypeInfo) field(st reflect.StructTag, fp string) error {
+	if bound(st) {
+		bti.Declared = append(bti.Declared, fp)
+	}
+	if _, ok := st.Lookup("status"); ok {
+		if bti.Status != "" {
+			return fmt.Errorf("%s: status code is bound to both %s and %s", bti.Typename, bti.Status, fp)
+		}
+		bti.Status = fp
+	}
+	if v, ok := st.Lookup("body"); ok {
+		if bti.Body != "" {
+			return fmt.Errorf("%s: raw body is bound to both %s and %s", bti.Typename, bti.Body, fp)
+		}
+		ct := cmp.Or(v, "application/octet-stream")
+		if _, _, err := mime.ParseMediaType(ct); err != nil {
+			return fmt.Errorf("%s: content type %q of %s: %w", bti.Typename, v, fp, err)
+		}
+		bti.Body = fp
+		bti.ContentType = ct
+	}
+	params := map[string]map[string]string{
+		"route":  bti.Params.Route,
+		"query":  bti.Params.Query,
+		"header": bti.Params.Header,
+		"cookie": bti.Params.Cookie,
+		"json":   bti.Params.Json,
+		"form":   bti.Params.Form,
+		"file":   bti.Params.File,
+	}
+	for _, src := range sources {
+		v, ok := st.Lookup(src)
+		if !ok {
+			continue
+		}
+		name, opts := split(v)
+		if src == "json" {
+			if v == "-" {
+				continue
+			}
+			// same as encoding/json, which also handles the options
+			name = cmp.Or(name, fp[strings.LastIndex(fp, ".")+1:])
+			opts = nil
+		}
+		encodings := 0
+		for _, opt := range opts {
+			switch {
+			case opt == "required" && slices.Contains(optional, src):
+				bti.Required[fp] = true
+			case strings.HasPrefix(opt, "default=") && slices.Contains(optional, src):
+				bti.Defaults[fp] = strings.TrimPrefix(opt, "default=")
+			case opt == "deep" && src == "query":
+				bti.Objects[name] = true
+				encodings++
+			case slices.Contains(ArrayEncodings, opt) && src == "query":
+				bti.Arrays[name] = opt
+				encodings++
+			default:
+				return fmt.Errorf("%s: unknown option %q in the %s tag of %s", bti.Typename, opt, src, fp)
+			}
+		}
+		if encodings > 1 {
+			return fmt.Errorf("%s: the query tag of %s can only have one of the deep, repeat and comma options", bti.Typename, fp)
+		}
+		if _, ok := bti.Defaults[fp]; ok && bti.Required[fp] {
+			return fmt.Errorf("%s: %s is both required and has a default", bti.Typename, fp)
+		}
+		if _, ok := bti.Defaults[fp]; ok && bti.Objects[name] && src == "query" {
+			return fmt.Errorf("%s: deep object %s can't have a default", bti.Typename, fp)
+		}
+		switch src {
+		case "header":
+			name = textproto.CanonicalMIMEHeaderKey(name)
+		case "cookie":
+			// otherwise [http.Request.AddCookie] silently drops it
+			if err := (&http.Cookie{Name: name}).Valid(); err != nil {
+				return fmt.Errorf("%s: cookie name %q of %s: %w", bti.Typename, name, fp, err)
 			}
 		}
+		if err := set(params[src], src, name, fp); err != nil {
+			return fmt.Errorf("%s: %w", bti.Typename, err)
+		}
 	}
+	return nil
+}
 
-	containsHeaderParams := len(bti.Params.Route) > 0 || len(bti.Params.Query) > 0
-	bti.ContainsBody = len(bti.Params.Json) > 0 || len(bti.Params.Form) > 0
+// json records the type and the tag of the json field for the views.
+// Unexported fields are left out as encoding/json does.
+func (bti *BindingTypeInfo) json(st reflect.StructTag, fp string, t ast.Expr, iss []*ast.ImportSpec) {
+	v, ok := st.Lookup("json")
+	if !ok || v == "-" || !token.IsExported(fp[strings.LastIndex(fp, ".")+1:]) {
+		return
+	}
+	bti.JsonTypes[fp] = t
+	bti.JsonTags[fp] = v
+	for _, is := range iss {
+		if !slices.ContainsFunc(bti.Imports, func(e *ast.ImportSpec) bool { return e.Path.Value == is.Path.Value }) {
+			bti.Imports = append(bti.Imports, is)
+		}
+	}
+}
+
+func (bti *BindingTypeInfo) conclude() (*BindingTypeInfo, error) {
+	containsHeaderParams := len(bti.Params.Route) > 0 || len(bti.Params.Query) > 0 || len(bti.Params.Header) > 0 || len(bti.Params.Cookie) > 0 || bti.Status != ""
+	bti.ContainsBody = len(bti.Params.Json) > 0 || len(bti.Params.Form) > 0 || len(bti.Params.File) > 0 || bti.Body != ""
 	bti.Empty = !bti.ContainsBody && !containsHeaderParams
 
 	if len(bti.Params.Json) > 0 && len(bti.Params.Form) > 0 {
@@ -135,9 +539,28 @@ func bti(rqtn string, ts *ast.TypeSpec) (*BindingTypeInfo, error) {
 		)
 	}
 
+	if bti.Body != "" {
+		others := slices.Sorted(maps.Values(bti.Params.Json))
+		others = append(others, slices.Sorted(maps.Values(bti.Params.Form))...)
+		others = append(others, slices.Sorted(maps.Values(bti.Params.File))...)
+		if len(others) > 0 {
+			return nil, fmt.Errorf("determining Content Type for body: both the raw body %s and the body fields {%s} found", bti.Body, strings.Join(others, ", "))
+		}
+	}
+
+	if len(bti.Params.Json) > 0 && len(bti.Params.File) > 0 {
+		return nil, fmt.Errorf("determining Content Type for body: both json {%s} and file {%s} tagged fields found",
+			join.Values(bti.Params.Json, ", "),
+			join.Values(bti.Params.File, ", "),
+		)
+	}
+
 	switch {
 	case len(bti.Params.Json) > 0:
 		bti.ContentType = "application/json"
+	case len(bti.Params.File) > 0:
+		bti.ContentType = "multipart/form-data"
+		bti.MaxMemory = DefaultMaxMemory
 	case len(bti.Params.Form) > 0:
 		bti.ContentType = "application/x-www-form-urlencoded"
 	}
@@ -145,29 +568,276 @@ func bti(rqtn string, ts *ast.TypeSpec) (*BindingTypeInfo, error) {
 	return bti, nil
 }
 
+func (src source) bti(rqtn string, ts *ast.TypeSpec) (*BindingTypeInfo, error) {
+	bti := newBindingTypeInfo(rqtn)
+	if st, ok := ts.Type.(*ast.StructType); ok {
+		if err := src.fields(bti, st, ""); err != nil {
+			return nil, err
+		}
+	}
+	return bti.conclude()
+}
+
+// embedded returns the name of embedded field
+func embedded(t ast.Expr) (string, bool) {
+	switch t := t.(type) {
+	case *ast.Ident:
+		return t.Name, false
+	case *ast.SelectorExpr:
+		return t.Sel.Name, false
+	case *ast.StarExpr:
+		n, _ := embedded(t.X)
+		return n, true
+	case *ast.IndexExpr:
+		return embedded(t.X)
+	case *ast.IndexListExpr:
+		return embedded(t.X)
+	}
+	return "", false
+}
+
+// imported checks if the embedded type is declared in another package
+func imported(t ast.Expr) bool {
+	switch t := t.(type) {
+	case *ast.SelectorExpr:
+		return true
+	case *ast.StarExpr:
+		return imported(t.X)
+	case *ast.IndexExpr:
+		return imported(t.X)
+	case *ast.IndexListExpr:
+		return imported(t.X)
+	}
+	return false
+}
+
+// fields adds the tagged fields of struct to bti. Fields of untagged
+// embedded structs are promoted, as long as they are declared in the
+// package. Embedded types of other packages are only resolved when the
+// package is type-checked.
+func (src source) fields(bti *BindingTypeInfo, st *ast.StructType, prefix string) error {
+	for _, f := range st.Fields.List {
+		tag := reflect.StructTag("")
+		if f.Tag != nil {
+			tag = reflect.StructTag(strings.Trim(f.Tag.Value, "`"))
+		}
+		if len(f.Names) == 0 {
+			n, ptr := embedded(f.Type)
+			if !tagged(tag) {
+				if imported(f.Type) {
+					continue
+				}
+				if ts, ok := src.findTypeSpec(n); ok {
+					if est, ok := ts.Type.(*ast.StructType); ok {
+						if ptr {
+							return fmt.Errorf("%s: promoting fields of embedded pointer %s is not supported", bti.Typename, n)
+						}
+						if err := src.fields(bti, est, prefix+n+"."); err != nil {
+							return err
+						}
+					}
+				}
+				continue
+			}
+			if err := bti.field(tag, prefix+n); err != nil {
+				return err
+			}
+			bti.json(tag, prefix+n, f.Type, src.imports(f.Type))
+			continue
+		}
+		for _, n := range f.Names {
+			if err := bti.field(tag, prefix+n.Name); err != nil {
+				return err
+			}
+			bti.json(tag, prefix+n.Name, f.Type, src.imports(f.Type))
+		}
+	}
+	return nil
+}
+
+// binding returns the info of the binding type named tn when the handler
+// mentions it in its body. The type can be declared in any file of the
+// package. The diagnostics are for the types found but not mentioned.
+func (src source) binding(h *ast.FuncDecl, tn string) (*BindingTypeInfo, []Diagnostic, error) {
+	if src.pkg != nil {
+		obj, ok := src.pkg.Scope().Lookup(tn).(*types.TypeName)
+		if !ok {
+			return nil, nil, nil
+		}
+		if !src.uses(h, obj) {
+			return nil, src.unmentioned(h, tn), nil
+		}
+		b, err := btiFromType(tn, obj.Type(), src.pkg)
+		return b, nil, err
+	}
+
+	ts, ok := src.findTypeSpec(tn)
+	if !ok {
+		return nil, nil, nil
+	}
+	if !checkBodyForIdent(h, ts.Name) {
+		return nil, src.unmentioned(h, tn), nil
+	}
+	b, err := src.bti(tn, ts)
+	return b, nil, err
+}
+
+// declared returns the info of the binding type declared in the package
+func (src source) declared(tn string) (*BindingTypeInfo, bool, error) {
+	if src.pkg != nil {
+		obj, ok := src.pkg.Scope().Lookup(tn).(*types.TypeName)
+		if !ok {
+			return nil, false, nil
+		}
+		b, err := btiFromType(tn, obj.Type(), src.pkg)
+		return b, true, err
+	}
+	ts, ok := src.findTypeSpec(tn)
+	if !ok {
+		return nil, false, nil
+	}
+	b, err := src.bti(tn, ts)
+	return b, true, err
+}
+
+// importPath returns the path of the package the file imports as name
+func importPath(f *ast.File, name string) string {
+	for _, is := range f.Imports {
+		if p, err := strconv.Unquote(is.Path.Value); err == nil && importName(f, p) == name {
+			return p
+		}
+	}
+	return ""
+}
+
+// external returns the info of the binding type declared in the package at
+// path. Without type information, the package is parsed for its syntax.
+func (src source) external(f *ast.File, path, tn string) (*BindingTypeInfo, bool, error) {
+	if src.pkg != nil {
+		for _, p := range src.pkg.Imports() {
+			if p.Path() == path {
+				b, found, err := source{pkg: p}.declared(tn)
+				if b != nil {
+					b.PackageName = p.Name()
+				}
+				return b, found, err
+			}
+		}
+		return nil, false, nil
+	}
+	dir := filepath.Dir(src.fset.Position(f.Pos()).Filename)
+	ps, err := packages.Load(&packages.Config{Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax, Dir: dir}, path)
+	if err != nil {
+		return nil, false, fmt.Errorf("loading package %s: %w", path, err)
+	}
+	if len(ps) != 1 || len(ps[0].Syntax) == 0 {
+		return nil, false, nil
+	}
+	files := map[string]*ast.File{}
+	for _, f := range ps[0].Syntax {
+		files[ps[0].Fset.Position(f.Pos()).Filename] = f
+	}
+	b, found, err := source{fset: ps[0].Fset, files: files}.declared(tn)
+	if b != nil {
+		b.PackageName = ps[0].Name
+	}
+	return b, found, err
+}
+
+// directed returns the info of the binding type specified with directive as
+// either a type of the package or an exported type of an imported package
+func (src source) directed(f *ast.File, h *ast.FuncDecl, directive, tn string) (*BindingTypeInfo, []Diagnostic, error) {
+	pos := src.fset.Position(h.Name.Pos())
+	unresolved := func(format string, a ...any) []Diagnostic {
+		return []Diagnostic{diagnose(pos, h, Error, CodeBindingUnresolved, "%s %s: %s", directive, tn, fmt.Sprintf(format, a...))}
+	}
+	pkgn, name, qualified := strings.Cut(tn, ".")
+	if !qualified {
+		b, found, err := src.declared(tn)
+		if !found {
+			return nil, unresolved("no such type in the package"), nil
+		}
+		return b, nil, err
+	}
+	if !token.IsExported(name) {
+		return nil, unresolved("the type is not exported"), nil
+	}
+	path := importPath(f, pkgn)
+	if path == "" {
+		return nil, unresolved("the file doesn't import a package named %s", pkgn), nil
+	}
+	b, found, err := src.external(f, path, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !found {
+		return nil, unresolved("no such type in %s", path), nil
+	}
+	b.Package = path
+	if b.Type != nil {
+		if missing := missingMethods(b.Type, bindingMethods[directive]); len(missing) > 0 {
+			return b, []Diagnostic{diagnose(pos, h, Warning, CodeBindingImported, "%s is declared in another package and missing methods: %s", tn, strings.Join(missing, ", "))}, nil
+		}
+		return b, nil, nil
+	}
+	return b, []Diagnostic{diagnose(pos, h, Notice, CodeBindingImported, "methods of %s are not generated as it is declared in another package", tn)}, nil
+}
+
+func (src source) unmentioned(h *ast.FuncDecl, tn string) []Diagnostic {
+	return []Diagnostic{diagnose(src.fset.Position(h.Name.Pos()), h, Warning, CodeBindingUnmentioned, "skipping %s as it is not mentioned in the handler body", tn)}
+}
+
+// receiverType returns the name of receiver type without the pointer and
+// type parameters, eg. "Store" for "*Store[T]"
 func receiverType(h *ast.FuncDecl) (string, error) {
 	if h.Recv == nil {
 		return "", nil
 	}
-	switch t := h.Recv.List[0].Type.(type) {
-	case *ast.StarExpr:
-		return t.X.(*ast.Ident).Name, nil
+	t := h.Recv.List[0].Type
+	if s, ok := t.(*ast.StarExpr); ok {
+		t = s.X
+	}
+	switch t := t.(type) {
 	case *ast.Ident:
 		return t.Name, nil
-	default:
-		return "", fmt.Errorf("unknown type (%T) found in receiver type detection for handler %q", t, h.Name.Name)
+	case *ast.IndexExpr:
+		if i, ok := t.X.(*ast.Ident); ok {
+			return i.Name, nil
+		}
+	case *ast.IndexListExpr:
+		if i, ok := t.X.(*ast.Ident); ok {
+			return i.Name, nil
+		}
 	}
+	return "", fmt.Errorf("unknown type (%T) found in receiver type detection for handler %q", t, h.Name.Name)
 }
 
 func recvn(s string) string {
 	return strings.ToLower(string(s[0:min(2, len(s))]))
 }
 
-func ref(h *ast.FuncDecl, recvt string) ast.Expr {
+func ref(h handler, recvt string, args []*ast.Field) ast.Expr {
+	var fn ast.Expr = h.ident
 	if h.Recv != nil {
-		return &ast.SelectorExpr{X: &ast.Ident{Name: recvn(recvt)}, Sel: h.Name}
+		fn = &ast.SelectorExpr{X: &ast.Ident{Name: recvn(recvt)}, Sel: h.ident}
 	}
-	return h.Name
+	switch h.kind {
+	case typeHandler:
+		return &ast.SelectorExpr{X: &ast.Ident{Name: recvn(recvt)}, Sel: &ast.Ident{Name: "ServeHTTP"}}
+	case factoryHandler:
+		call := &ast.CallExpr{Fun: fn}
+		for _, a := range args {
+			call.Args = append(call.Args, a.Names[0])
+			if _, ok := a.Type.(*ast.Ellipsis); ok {
+				call.Ellipsis = 1
+			}
+		}
+		if h.returns == "Handler" {
+			return &ast.SelectorExpr{X: call, Sel: &ast.Ident{Name: "ServeHTTP"}}
+		}
+		return call
+	}
+	return fn
 }
 
 func findTypeSpec(f *ast.File, n string) (*ast.TypeSpec, bool) {
@@ -183,6 +853,26 @@ func findTypeSpec(f *ast.File, n string) (*ast.TypeSpec, bool) {
 	return nil, false
 }
 
+// imports returns the imports of the file the expression is in, which the
+// expression refers to
+func (src source) imports(e ast.Expr) []*ast.ImportSpec {
+	for _, f := range src.files {
+		if f.FileStart <= e.Pos() && e.Pos() <= f.FileEnd {
+			return imports(f, []*ast.Field{{Type: e}})
+		}
+	}
+	return nil
+}
+
+func (src source) findTypeSpec(n string) (*ast.TypeSpec, bool) {
+	for _, f := range src.files {
+		if ts, ok := findTypeSpec(f, n); ok {
+			return ts, true
+		}
+	}
+	return nil, false
+}
+
 func checkBodyForIdent(h *ast.FuncDecl, i *ast.Ident) bool {
 	found := false
 	ast.Inspect(h.Body, func(n ast.Node) bool {
@@ -194,64 +884,6 @@ func checkBodyForIdent(h *ast.FuncDecl, i *ast.Ident) bool {
 	return found
 }
 
-var methods = []string{
-	http.MethodGet,
-	http.MethodHead,
-	http.MethodPost,
-	http.MethodPut,
-	http.MethodPatch,
-	http.MethodDelete,
-	http.MethodConnect,
-	http.MethodOptions,
-	http.MethodTrace,
-}
-
-type Mode string
-
-func (m Mode) Ignore() bool {
-	return m == "ignore"
-}
-
-func (m Mode) ToList() bool {
-	return m != "ignore" && m == "list"
-}
-
-func (m Mode) ParseBindings() bool {
-	return m != "ignore" && m != "list"
-}
-
-type Doc struct {
-	Method, Path string
-	Mode         Mode
-}
-
-var whitespaces = regexp.MustCompile(`\s+`)
-
-func parseDoc(fd *ast.FuncDecl) Doc {
-	doc := Doc{}
-	if fd.Doc != nil {
-		for _, c := range fd.Doc.List {
-			line := c.Text
-			line = strings.TrimPrefix(line, "//")
-			line = strings.TrimPrefix(line, "/*")
-			line = strings.TrimPrefix(line, "*")
-			line = strings.TrimSpace(line)
-			line = whitespaces.ReplaceAllString(line, " ")
-			for i, word := range strings.Split(line, " ") {
-				switch {
-				case strings.HasPrefix(word, "gh:") && i == 0:
-					doc.Mode = Mode(strings.TrimPrefix(word, "gh:"))
-				case slices.Contains(methods, word) && i == 0:
-					doc.Method = word
-				case strings.HasPrefix(word, "/") && i <= 1:
-					doc.Path = word
-				}
-			}
-		}
-	}
-	return doc
-}
-
 func has[K comparable, V any](m map[K]V, k K) bool {
 	_, ok := m[k]
 	return ok
@@ -283,10 +915,10 @@ var methodMap = map[string]string{
 	"Trace":   "TRACE",
 }
 
-func decideMethodFromHandlerName(h *ast.FuncDecl) string {
+func (c Conventions) decideMethodFromHandlerName(h *ast.FuncDecl) string {
 	matches := titles.FindStringSubmatch(h.Name.Name)
-	if len(matches) > 1 && has(methodMap, matches[1]) {
-		return methodMap[matches[1]]
+	if len(matches) > 1 && has(c.verbs(), matches[1]) {
+		return c.verbs()[matches[1]]
 	}
 	return ""
 }
@@ -309,23 +941,23 @@ func electMethod(docComment, handlerName, requestBinding string) string {
 	return cmp.Or(docComment, handlerName, requestBinding, string(http.MethodGet))
 }
 
-func handlerMethod(h *ast.FuncDecl, doc Doc, rti *BindingTypeInfo, filename string) (string, []string) {
+func (c Conventions) handlerMethod(h *ast.FuncDecl, doc Doc, rti *BindingTypeInfo, pos token.Position) (string, []Diagnostic) {
 	fromBindingType := ""
 	if rti != nil && doc.Mode.ParseBindings() {
 		fromBindingType = decideMethodFromRequest(rti)
 	}
-	fromHandlerName := decideMethodFromHandlerName(h)
+	fromHandlerName := c.decideMethodFromHandlerName(h)
 	method := electMethod(doc.Method, fromHandlerName, fromBindingType)
 
 	okDoc := doc.Method != ""
 	okName := fromHandlerName != ""
 	okBq := fromBindingType != ""
 
-	complaints := []string{}
+	complaints := []Diagnostic{}
 
 	if okDoc && okName {
 		if doc.Method != fromHandlerName {
-			complaints = append(complaints, fmt.Sprintf("%s: %s:%s: handler name implies %q but doc comment specifies %q", WARNING, filename, h.Name.Name, fromHandlerName, doc.Method))
+			complaints = append(complaints, diagnose(pos, h, Warning, CodeMethodNameConflict, "handler name implies %q but doc comment specifies %q", fromHandlerName, doc.Method))
 		}
 	}
 
@@ -334,17 +966,17 @@ func handlerMethod(h *ast.FuncDecl, doc Doc, rti *BindingTypeInfo, filename stri
 		// TODO: decide if assigning method by handler name prefix implicit?
 		if !okDoc && !okName {
 			if okBq {
-				complaints = append(complaints, fmt.Sprintf("%s: %s:%s: implicitly assigned %q based on if the request contains a body", NOTICE, filename, h.Name.Name, method))
+				complaints = append(complaints, diagnose(pos, h, Notice, CodeMethodImplicit, "implicitly assigned %q based on if the request contains a body", method))
 			} else {
-				complaints = append(complaints, fmt.Sprintf("%s: %s:%s: implicitly assigned %q without any information", NOTICE, filename, h.Name.Name, method))
+				complaints = append(complaints, diagnose(pos, h, Notice, CodeMethodImplicit, "implicitly assigned %q without any information", method))
 			}
 		}
 
 		if !slices.Contains(bodied, method) && slices.Contains(bodied, fromBindingType) {
-			complaints = append(complaints, fmt.Sprintf("%s: %s:%s: assigned %q but the request binding type contains a body", ERROR, filename, h.Name.Name, method))
+			complaints = append(complaints, diagnose(pos, h, Error, CodeMethodBodyConflict, "assigned %q but the request binding type contains a body", method))
 		}
 		if slices.Contains(bodied, method) && !slices.Contains(bodied, fromBindingType) {
-			complaints = append(complaints, fmt.Sprintf("%s: %s:%s: assigned %q but the request binding type doesn't contain a body", ERROR, filename, h.Name.Name, method))
+			complaints = append(complaints, diagnose(pos, h, Error, CodeMethodBodyConflict, "assigned %q but the request binding type doesn't contain a body", method))
 		}
 	}
 
@@ -352,11 +984,20 @@ func handlerMethod(h *ast.FuncDecl, doc Doc, rti *BindingTypeInfo, filename stri
 }
 
 func kebab(input string) string {
+	return delimited(input, '-')
+}
+
+func snake(input string) string {
+	return delimited(input, '_')
+}
+
+// delimited lowercases the words of camel case input and joins them with sep
+func delimited(input string, sep rune) string {
 	var result strings.Builder
 	for i, r := range input {
 		if unicode.IsUpper(r) {
 			if i != 0 {
-				result.WriteRune('-')
+				result.WriteRune(sep)
 			}
 			result.WriteRune(unicode.ToLower(r))
 		} else {
@@ -366,49 +1007,53 @@ func kebab(input string) string {
 	return result.String()
 }
 
-func handlerPathFromBindingType(h *ast.FuncDecl, rti *BindingTypeInfo) string {
-	ps := []string{}
-	if rti != nil {
-		for i := range rti.Params.Route {
-			ps = append(ps, fmt.Sprintf("{%s}", i))
-		}
-	}
-
-	path := fmt.Sprintf("/%s", kebab(h.Name.Name))
-	if len(ps) > 0 {
-		slices.Sort(ps)
-		path = fmt.Sprintf("%s/%s", path, strings.Join(ps, "/"))
-	}
-	return path
-}
-
-func checkHandlerPathInDoc(doc Doc, rti *BindingTypeInfo) (missing []string) {
+// missingRouteParams returns the route parameters of binding type that
+// the pattern doesn't have a wildcard for, in the declaration order
+func missingRouteParams(p *Pattern, rti *BindingTypeInfo) (missing []string) {
 	if rti == nil || rti.Params.Route == nil {
 		return
 	}
-	words := strings.Split(strings.TrimPrefix(doc.Path, "/"), "/")
-	for param := range maps.Keys(rti.Params.Route) {
-		if !slices.Contains(words, fmt.Sprintf("{%s}", param)) {
+	ws := p.Wildcards()
+	for _, param := range rti.InOrder(rti.Params.Route) {
+		if !slices.Contains(ws, param) {
 			missing = append(missing, param)
 		}
 	}
 	return
 }
 
-func handlerPath(h *ast.FuncDecl, doc Doc, rti *BindingTypeInfo, filename string) (string, string) {
-	if doc.Path == "" {
-		return handlerPathFromBindingType(h, rti), ""
+// handlerPath prepends the prefix of receiver type to the path, which
+// is derived from the handler name when unspecified, and parses it.
+// The missing route parameters are appended unless the path ends with
+// a slash, "{$}" or "{x...}". Returns nil for invalid patterns.
+func (c Conventions) handlerPath(h *ast.FuncDecl, doc Doc, recvt, prefix string, rti *BindingTypeInfo, pos token.Position) (*Pattern, []Diagnostic) {
+	s := cmp.Or(prefix+c.derive(h.Name.Name, recvt, prefix != ""), "/")
+	if doc.Path != "" {
+		i := strings.IndexByte(doc.Path, '/')
+		s = doc.Path[:i] + prefix + doc.Path[i:]
 	}
-	missings := checkHandlerPathInDoc(doc, rti)
-	if len(missings) > 0 {
-		complaint := fmt.Sprintf("%s: %s:%s: the path specified in doc comment has been added missing route parameters: %s", NOTICE, filename, h.Name.Name, strings.Join(missings, ", "))
-		suffix := ""
-		for _, missing := range missings {
-			suffix += fmt.Sprintf("/{%s}", missing)
-		}
-		return filepath.Join(doc.Path, suffix), complaint
+	p, err := ParsePattern(s)
+	if err != nil {
+		return nil, []Diagnostic{diagnose(pos, h, Error, CodePathInvalid, "invalid path %q: %s", s, err)}
+	}
+	missings := missingRouteParams(p, rti)
+	if len(missings) == 0 {
+		return p, nil
+	}
+	if p.Subtree() || p.Anchored() {
+		return nil, []Diagnostic{diagnose(pos, h, Error, CodePathInvalid, "the path %q lacks the route parameters and can't be appended: %s", s, strings.Join(missings, ", "))}
 	}
-	return doc.Path, ""
+	for _, missing := range missings {
+		s += fmt.Sprintf("/{%s}", missing)
+	}
+	p, err = ParsePattern(s)
+	if err != nil {
+		return nil, []Diagnostic{diagnose(pos, h, Error, CodePathInvalid, "invalid path %q: %s", s, err)}
+	}
+	if doc.Path == "" {
+		return p, nil
+	}
+	return p, []Diagnostic{diagnose(pos, h, Notice, CodePathParamsAppended, "the path specified in doc comment has been added missing route parameters: %s", strings.Join(missings, ", "))}
 }
 
 type Receiver struct {
@@ -417,90 +1062,286 @@ type Receiver struct {
 
 type Info struct {
 	Method       string
-	Path         string
+	Path         string   // host and path parts of the pattern
+	Pattern      *Pattern // parsed Path
 	Ref          ast.Expr
 	RequestType  *BindingTypeInfo
 	ResponseType *BindingTypeInfo
+	Status       int      // status code of successful responses; zero for unspecified
+	Tags         []string // specified with gh:tag
+
+	Recv ast.Expr // receiver type as declared, eg. "*Store[T]"; nil for functions
+
+	// parameters of handler factories; listers take them to call the factory
+	Args    []*ast.Field
+	Imports []*ast.ImportSpec // the imports the types of Args refer to
+}
+
+// Package is the result of inspecting a package
+type Package struct {
+	Name        string
+	Handlers    map[Receiver]map[string]Info
+	Diagnostics []Diagnostic
+}
+
+// source is the package content the inspection runs on. pkg and info are
+// only available when the package is loaded with type information.
+type source struct {
+	fset  *token.FileSet
+	files map[string]*ast.File // filename -> file
+	pkg   *types.Package
+	info  *types.Info
+	conv  Conventions
+}
+
+// test files can't declare handlers and external test packages would
+// otherwise fail the single package check
+func notTest(fi fs.FileInfo) bool {
+	return !strings.HasSuffix(fi.Name(), "_test.go")
 }
 
-func Dir(dir string, verbose bool) (map[Receiver]map[string]Info, string, error) {
-	d, err := parser.ParseDir(token.NewFileSet(), dir, nil, parser.AllErrors|parser.ParseComments)
+// Dir inspects the package in dir with the default conventions
+func Dir(dir string) (*Package, error) {
+	return Conventions{}.Dir(dir)
+}
+
+// Dir inspects the package in dir by parsing its files
+func (c Conventions) Dir(dir string) (*Package, error) {
+	fset := token.NewFileSet()
+	d, err := parser.ParseDir(fset, dir, notTest, parser.AllErrors|parser.ParseComments)
 	if err != nil {
-		return nil, "", fmt.Errorf("parsing files in directory: %w", err)
+		return nil, fmt.Errorf("parsing files in directory: %w", err)
 	}
 
 	if len(d) > 1 {
-		return nil, "", fmt.Errorf("found more than one packages: %s", join.Keys(d, ", "))
+		return nil, fmt.Errorf("found more than one packages: %s", join.Keys(d, ", "))
 	} else if len(d) == 0 {
-		return nil, "", fmt.Errorf("no packages found")
+		return nil, fmt.Errorf("no packages found")
 	}
 	p := d[first(maps.Keys(d))]
 
+	infoss, ds, err := inspect(source{fset: fset, files: p.Files, conv: c})
+	if err != nil {
+		return nil, err
+	}
+	return &Package{
+		Name:        first(maps.Values(p.Files)).Name.Name,
+		Handlers:    infoss,
+		Diagnostics: ds,
+	}, nil
+}
+
+func sortDiagnostics(ds []Diagnostic) {
+	slices.SortStableFunc(ds, func(a, b Diagnostic) int {
+		return cmp.Or(
+			cmp.Compare(a.Pos.Filename, b.Pos.Filename),
+			cmp.Compare(a.Pos.Line, b.Pos.Line),
+			cmp.Compare(a.Pos.Column, b.Pos.Column),
+		)
+	})
+}
+
+// entry is a handler collected for the checks involving multiple handlers
+type entry struct {
+	recv Receiver
+	h    *ast.FuncDecl
+	pos  token.Position
+	info Info
+}
+
+// sortEntries sorts in the order of receiver types and handler names,
+// which is also the order listers register the handlers
+func sortEntries(ss []entry) {
+	slices.SortFunc(ss, func(a, b entry) int {
+		return cmp.Or(cmp.Compare(a.recv.Type, b.recv.Type), cmp.Compare(a.h.Name.Name, b.h.Name.Name))
+	})
+}
+
+// shared reports the handlers sharing a binding type with a handler on
+// different method, path or status, as the Build and Write methods are
+// generated for the first handler in the order of receiver types and names
+func shared(requests, responses map[string][]entry) []Diagnostic {
+	ds := []Diagnostic{}
+	for tn, ss := range requests {
+		sortEntries(ss)
+		for _, s := range ss[1:] {
+			if s.info.Method != ss[0].info.Method || s.info.Path != ss[0].info.Path {
+				ds = append(ds, diagnose(s.pos, s.h, Warning, CodeBindingShared, "%s.Build sends the request to %s %s of %s instead of %s %s",
+					tn, ss[0].info.Method, ss[0].info.Path, ss[0].h.Name.Name, s.info.Method, s.info.Path))
+			}
+		}
+	}
+	for tn, ss := range responses {
+		sortEntries(ss)
+		for _, s := range ss[1:] {
+			if cmp.Or(s.info.Status, http.StatusOK) != cmp.Or(ss[0].info.Status, http.StatusOK) {
+				ds = append(ds, diagnose(s.pos, s.h, Warning, CodeBindingShared, "%s.Write responds with the status %d of %s instead of %d",
+					tn, cmp.Or(ss[0].info.Status, http.StatusOK), ss[0].h.Name.Name, cmp.Or(s.info.Status, http.StatusOK)))
+			}
+		}
+	}
+	return ds
+}
+
+// typeDocs parses the doc comments of type declarations
+func (src source) typeDocs() (map[string]Doc, []Diagnostic) {
+	docs := map[string]Doc{}
+	ds := []Diagnostic{}
+	for _, f := range src.files {
+		for _, d := range f.Decls {
+			gd, ok := d.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, s := range gd.Specs {
+				ts := s.(*ast.TypeSpec)
+				cg := ts.Doc
+				if cg == nil && !gd.Lparen.IsValid() {
+					cg = gd.Doc
+				}
+				doc := parseTypeDoc(cg)
+				for _, is := range doc.issues {
+					ds = append(ds, Diagnostic{
+						Pos:      src.fset.Position(is.pos),
+						Severity: Error,
+						Code:     CodeDirective,
+						Handler:  ts.Name.Name,
+						Message:  is.msg,
+					})
+				}
+				docs[ts.Name.Name] = doc
+			}
+		}
+	}
+	return docs, ds
+}
+
+// tags merges the tags of receiver type and handler
+func tags(recv, h []string) []string {
+	if len(recv) == 0 {
+		return h
+	}
+	ts := slices.Clone(recv)
+	for _, t := range h {
+		if !slices.Contains(ts, t) {
+			ts = append(ts, t)
+		}
+	}
+	return ts
+}
+
+func inspect(src source) (map[Receiver]map[string]Info, []Diagnostic, error) {
 	infoss := map[Receiver]map[string]Info{}
-	for fn, f := range p.Files {
-		for _, h := range findHandlers(f) {
-			doc := parseDoc(h)
+	diagnostics := []Diagnostic{}
+	requests, responses := map[string][]entry{}, map[string][]entry{}
+	routes := []entry{}
+	recvdocs, ds := src.typeDocs()
+	diagnostics = append(diagnostics, ds...)
+	hss := map[*ast.File][]handler{}
+	for _, f := range src.files {
+		hss[f] = src.findHandlers(f)
+	}
+	plain := receivers(hss)
+	for _, f := range src.files {
+		for _, h := range hss[f] {
+			pos := src.fset.Position(h.Name.Pos())
+			doc := parseDoc(h.FuncDecl)
+			for _, is := range doc.issues {
+				diagnostics = append(diagnostics, diagnose(src.fset.Position(is.pos), h.FuncDecl, Error, CodeDirective, "%s", is.msg))
+			}
+			if doc.Name != "" {
+				h = h.rename(doc.Name)
+			}
 			if doc.Mode.Ignore() {
-				if verbose {
-					fmt.Fprintf(os.Stderr, "%s: ignoring %s:%s\n", NOTICE, fn, h.Name.Name)
-				}
+				diagnostics = append(diagnostics, diagnose(pos, h.FuncDecl, Notice, CodeIgnored, "ignoring the handler"))
 				continue
 			}
-
-			recvt, err := receiverType(h)
+			recvt, err := receiverType(h.FuncDecl)
 			if err != nil {
-				return nil, "", fmt.Errorf("inspecting receiver type of handler: %w", err)
+				return nil, nil, fmt.Errorf("inspecting receiver type of handler: %w", err)
+			}
+			if h.kind != funcHandler && !plain[recvt] && !doc.directed() && !recvdocs[recvt].directed() {
+				diagnostics = append(diagnostics, diagnose(pos, h.FuncDecl, Notice, CodeHandlerUndirected, "not listing the %s without directives, as its receiver has no other handlers", h.kind))
+				continue
 			}
+			if h.variadic() {
+				diagnostics = append(diagnostics, diagnose(pos, h.FuncDecl, Error, CodeFactoryVariadic, "variadic factories can't be called by listers; take a slice instead"))
+				continue
+			}
+
 			i := Info{
-				Ref: ref(h, recvt),
+				Status: doc.Status,
+				Tags:   tags(recvdocs[recvt].Tags, doc.Tags),
+				Args:   h.args(),
 			}
+			if h.Recv != nil {
+				i.Recv = h.Recv.List[0].Type
+			}
+			i.Ref = ref(h, recvt, i.Args)
+			i.Imports = imports(f, i.Args)
 
 			if doc.Mode.ParseBindings() {
 				bqtn := fmt.Sprintf("%sRequest", h.Name.Name)
-				bq, ok := findTypeSpec(f, bqtn)
-				if ok && checkBodyForIdent(h, bq.Name) {
-					i.RequestType, err = bti(bqtn, bq)
-					if err != nil {
-						return nil, "", fmt.Errorf("inspecting request binding type: %w", err)
-					}
+				var ds []Diagnostic
+				if doc.Request != "" {
+					i.RequestType, ds, err = src.directed(f, h.FuncDecl, "gh:request", doc.Request)
+				} else {
+					i.RequestType, ds, err = src.binding(h.FuncDecl, bqtn)
 				}
-			}
-
-			method, complaints := handlerMethod(h, doc, i.RequestType, fn)
-			for _, complaint := range complaints {
-				if verbose || strings.HasPrefix(complaint, ERROR) || strings.HasPrefix(complaint, WARNING) {
-					fmt.Fprintln(os.Stderr, complaint)
+				if err != nil {
+					return nil, nil, fmt.Errorf("inspecting request binding type: %w", err)
 				}
+				diagnostics = append(diagnostics, ds...)
+				src.conv.body(i.RequestType)
 			}
+
+			method, ds := src.conv.handlerMethod(h.FuncDecl, doc, i.RequestType, pos)
+			diagnostics = append(diagnostics, ds...)
+			diagnostics = append(diagnostics, checkFieldMethods(h.FuncDecl, i.RequestType, requested, pos)...)
+			diagnostics = append(diagnostics, checkBodyField(h.FuncDecl, i.RequestType, pos)...)
 			i.Method = method
 
-			path, complaint := handlerPath(h, doc, i.RequestType, fn)
-			if complaint != "" && verbose {
-				fmt.Fprintln(os.Stderr, complaint)
+			pattern, ds := src.conv.handlerPath(h.FuncDecl, doc, recvt, recvdocs[recvt].Prefix, i.RequestType, pos)
+			diagnostics = append(diagnostics, ds...)
+			if pattern == nil { // reported as error, which fails the commands
+				continue
 			}
-			i.Path = path
+			i.Path = pattern.Host + pattern.Path
+			i.Pattern = pattern
 
 			if doc.Mode.ParseBindings() {
 				bstn := fmt.Sprintf("%sResponse", h.Name.Name)
-				bs, ok := findTypeSpec(f, bstn)
-				if ok && checkBodyForIdent(h, bs.Name) {
-					i.ResponseType, err = bti(bstn, bs)
-					if err != nil {
-						return nil, "", fmt.Errorf("inspecting response binding type: %w", err)
-					}
+				var ds []Diagnostic
+				if doc.Response != "" {
+					i.ResponseType, ds, err = src.directed(f, h.FuncDecl, "gh:response", doc.Response)
+				} else {
+					i.ResponseType, ds, err = src.binding(h.FuncDecl, bstn)
 				}
+				if err != nil {
+					return nil, nil, fmt.Errorf("inspecting response binding type: %w", err)
+				}
+				diagnostics = append(diagnostics, ds...)
+				diagnostics = append(diagnostics, checkFieldMethods(h.FuncDecl, i.ResponseType, responded, pos)...)
+				diagnostics = append(diagnostics, checkStatusField(h.FuncDecl, i.ResponseType, pos)...)
+				src.conv.body(i.ResponseType)
 			}
 
-			if verbose {
-				fmt.Printf("adding %s %s for %s\n", i.Method, i.Path, h.Name.Name)
-			}
 			r := Receiver{recvn(recvt), recvt}
 			if _, ok := infoss[r]; !ok {
 				infoss[r] = map[string]Info{}
 			}
 			infoss[r][h.Name.Name] = i
+			routes = append(routes, entry{r, h.FuncDecl, pos, i})
+			if i.RequestType.Local() {
+				requests[i.RequestType.Typename] = append(requests[i.RequestType.Typename], entry{r, h.FuncDecl, pos, i})
+			}
+			if i.ResponseType.Local() {
+				responses[i.ResponseType.Typename] = append(responses[i.ResponseType.Typename], entry{r, h.FuncDecl, pos, i})
+			}
 		}
 	}
-
-	return infoss, first(maps.Values(p.Files)).Name.Name, nil
+	diagnostics = append(diagnostics, shared(requests, responses)...)
+	diagnostics = append(diagnostics, conflicts(routes)...)
+	sortDiagnostics(diagnostics)
+	return infoss, diagnostics, nil
 }
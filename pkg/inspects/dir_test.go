@@ -1,11 +1,15 @@
 package inspects
 
 import (
+	"cmp"
 	"fmt"
 	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
 	"maps"
 	"net/http"
-	"os"
+	"reflect"
 	"slices"
 	"strings"
 	"testing"
@@ -76,6 +80,31 @@ func TestParseDoc(t *testing.T) {
 			input:       []string{"//   gh:ignore    ", "// GET     /index.html   "},
 			output:      Doc{Method: GET, Path: "/index.html", Mode: "ignore"},
 		},
+		{
+			description: "directives",
+			input:       []string{"// gh:method PUT", "// gh:path /pets/{id}", "// gh:name Replace", "// gh:status 202", "// gh:tag pets", "// gh:tag admin pets"},
+			output:      Doc{Method: "PUT", Path: "/pets/{id}", Name: "Replace", Status: 202, Tags: []string{"pets", "admin"}},
+		},
+		{
+			description: "host and wildcards",
+			input:       []string{"// GET api.example.com/files/{path...}"},
+			output:      Doc{Method: GET, Path: "api.example.com/files/{path...}"},
+		},
+		{
+			description: "block comment",
+			input:       []string{"/*\n * gh:list\n * GET /pets\n */"},
+			output:      Doc{Method: GET, Path: "/pets", Mode: "list"},
+		},
+		{
+			description: "prose",
+			input:       []string{"// Create creates a pet, see /docs", "// GET returns nothing here"},
+			output:      Doc{},
+		},
+		{
+			description: "binding types",
+			input:       []string{"// GET /index.html", "// gh:request users.CreateInput", "//  gh:response   User "},
+			output:      Doc{Method: GET, Path: "/index.html", Request: "users.CreateInput", Response: "User"},
+		},
 	}
 
 	for _, tc := range tcs {
@@ -95,6 +124,63 @@ func TestParseDoc(t *testing.T) {
 			if got.Path != tc.output.Path {
 				t.Errorf(".Path: expected '%v' got '%v'", tc.output.Path, got.Path)
 			}
+			if got.Name != tc.output.Name {
+				t.Errorf(".Name: expected '%v' got '%v'", tc.output.Name, got.Name)
+			}
+			if got.Status != tc.output.Status {
+				t.Errorf(".Status: expected '%v' got '%v'", tc.output.Status, got.Status)
+			}
+			if slices.Compare(got.Tags, tc.output.Tags) != 0 {
+				t.Errorf(".Tags: expected '%v' got '%v'", tc.output.Tags, got.Tags)
+			}
+			if got.Mode.ToList() != tc.output.Mode.ToList() {
+				t.Errorf(".ToList: expected '%v' got '%v'", tc.output.Mode.ToList(), got.Mode.ToList())
+			}
+			if len(got.issues) > 0 {
+				t.Errorf("unexpected issues: %v", got.issues)
+			}
+			if got.Request != tc.output.Request {
+				t.Errorf(".Request: expected '%v' got '%v'", tc.output.Request, got.Request)
+			}
+			if got.Response != tc.output.Response {
+				t.Errorf(".Response: expected '%v' got '%v'", tc.output.Response, got.Response)
+			}
+		})
+	}
+}
+
+func TestParseDoc_issues(t *testing.T) {
+	tcs := map[string][]string{
+		"unknown directive gh:lsit":                         {"// gh:lsit"},
+		"unknown method PSOT":                               {"// PSOT /pets"},
+		"unknown method GETS":                               {"// gh:method GETS"},
+		"gh:ignore takes no arguments":                      {"// gh:ignore all"},
+		"gh:path takes one argument":                        {"// gh:path"},
+		"gh:tag takes at least one argument":                {"// gh:tag"},
+		"gh:path pets: host/path missing /":                 {"// gh:path pets"},
+		"gh:name 1st is not a valid identifier":             {"// gh:name 1st"},
+		"gh:status 999 is not a valid status code":          {"// gh:status 999"},
+		"gh:path /b conflicts with the earlier \"/a\"":      {"// GET /a", "// gh:path /b"},
+		"gh:path /a/{x}/{x}: duplicate wildcard name \"x\"": {"// gh:path /a/{x}/{x}"},
+		"gh:prefix is only applicable to receiver types":    {"// gh:prefix /pets"},
+		"gh:list conflicts with the earlier gh:ignore":      {"// gh:ignore", "// gh:list"},
+	}
+	for expected, input := range tcs {
+		t.Run(expected, func(t *testing.T) {
+			list := []*ast.Comment{}
+			for i, line := range input {
+				list = append(list, &ast.Comment{Slash: token.Pos(i*10 + 1), Text: line})
+			}
+			got := parseDoc(&ast.FuncDecl{Doc: &ast.CommentGroup{List: list}})
+			if len(got.issues) != 1 {
+				t.Fatalf("expected one issue got %v", got.issues)
+			}
+			if got.issues[0].msg != expected {
+				t.Errorf("expected %q got %q", expected, got.issues[0].msg)
+			}
+			if got.issues[0].pos != list[len(list)-1].Slash {
+				t.Errorf("expected the issue to be positioned at the last line")
+			}
 		})
 	}
 }
@@ -121,7 +207,7 @@ func TestDecideMethodFromHandlerName(t *testing.T) {
 	}
 	for _, tc := range handlers {
 		t.Run(string(tc.input), func(t *testing.T) {
-			method := decideMethodFromHandlerName(&ast.FuncDecl{Name: ast.NewIdent(string(tc.input))})
+			method := Conventions{}.decideMethodFromHandlerName(&ast.FuncDecl{Name: ast.NewIdent(string(tc.input))})
 			if string(tc.output) != method {
 				t.Fatalf("expected %q got %q", tc.output, method)
 			}
@@ -174,9 +260,9 @@ func TestHandlerMethodComplaints(t *testing.T) {
 				h.Doc = &ast.CommentGroup{List: []*ast.Comment{{Text: fmt.Sprintf("// %s", tc.docComment)}}}
 			}
 			doc := parseDoc(h)
-			_, complaints := handlerMethod(h, doc, bti, "")
+			_, complaints := Conventions{}.handlerMethod(h, doc, bti, token.Position{})
 			for _, expectation := range tc.contains {
-				if !slices.ContainsFunc(complaints, func(complaint string) bool { return strings.Contains(complaint, expectation) }) {
+				if !slices.ContainsFunc(complaints, func(complaint Diagnostic) bool { return strings.Contains(complaint.Message, expectation) }) {
 					t.Errorf("method: expected to contain %q, got %q", expectation, complaints)
 				}
 			}
@@ -232,28 +318,1225 @@ func TestHandlerMethod(t *testing.T) {
 				h.Doc = &ast.CommentGroup{List: []*ast.Comment{{Text: fmt.Sprintf("// %s", tc.docComment)}}}
 			}
 			doc := parseDoc(h)
-			method, complaints := handlerMethod(h, doc, bti, "")
+			method, complaints := Conventions{}.handlerMethod(h, doc, bti, token.Position{})
 			if method != tc.expected {
 				t.Errorf("method: expected %q, got %q", tc.expected, method)
 			}
 			if (complaints != nil) != tc.complain {
 				t.Errorf("complaints: expected %v, got %v", tc.complain, complaints != nil)
-				t.Log(strings.Join(complaints, "\n"))
+				t.Log(complaints)
 			}
 		})
 	}
 }
 
 func TestDir_petstore(t *testing.T) {
-	os.Stderr, _ = os.Open(os.DevNull) // silence printed errors
-
-	infoss, _, err := Dir("testdata/petstore", false)
+	p, err := Dir("testdata/petstore")
 	if err != nil {
 		t.Fatalf("act: Dir: %v", err)
 	}
 
-	petstore := first(maps.Values(infoss))
+	petstore := first(maps.Values(p.Handlers))
 	if l := len(petstore); l != 4 {
 		t.Fatalf("expected 4 got %d", l)
 	}
 }
+
+func TestLoad_petstore(t *testing.T) {
+	p, err := Load("testdata/petstore")
+	if err != nil {
+		t.Fatalf("act: Load: %v", err)
+	}
+
+	if p.Name != "petstore" {
+		t.Errorf("package name: expected %q got %q", "petstore", p.Name)
+	}
+	petstore := first(maps.Values(p.Handlers))
+	if l := len(petstore); l != 4 {
+		t.Fatalf("expected 4 got %d", l)
+	}
+}
+
+func TestLoad_typed(t *testing.T) {
+	p, err := Load("testdata/typed")
+	if err != nil {
+		t.Fatalf("act: Load: %v", err)
+	}
+
+	get, ok := p.Handlers[Receiver{"pe", "Pets"}]["Get"]
+	if !ok {
+		t.Fatalf("handler Get not found")
+	}
+	if get.RequestType == nil || get.RequestType.Type == nil {
+		t.Fatalf("expected request binding type with type information")
+	}
+	if get.ResponseType == nil || len(get.ResponseType.Params.Json) != 1 {
+		t.Fatalf("expected the aliased response binding type to be resolved with its json field")
+	}
+
+	if !slices.ContainsFunc(p.Diagnostics, func(d Diagnostic) bool {
+		return d.Code == CodeFieldMethods && d.Severity == Error && d.Handler == "Get" &&
+			strings.Contains(d.Message, "GetRequest.Name is missing methods: FromQuery, ToQuery, Validate")
+	}) {
+		t.Errorf("expected diagnostic about the Name field, got %v", p.Diagnostics)
+	}
+}
+
+func TestLoad_typeErrors(t *testing.T) {
+	p, err := Load("testdata/broken")
+	if err != nil {
+		t.Fatalf("act: Load: %v", err)
+	}
+	if _, ok := p.Handlers[Receiver{"pe", "Pets"}]["List"]; !ok {
+		t.Errorf("expected the handler of the package with type errors")
+	}
+	if !slices.ContainsFunc(p.Diagnostics, func(d Diagnostic) bool {
+		return d.Code == CodeTypeError && strings.Contains(d.Message, "p.Missing undefined")
+	}) {
+		t.Errorf("expected the type error as diagnostic, got %v", p.Diagnostics)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	dirs, err := Match("../validator/...")
+	if err != nil {
+		t.Fatalf("act: Match: %v", err)
+	}
+	got := []string{}
+	for _, d := range dirs {
+		got = append(got, d.Path)
+	}
+	expected := []string{
+		"go.ufukty.com/gohandlers/pkg/validator",
+		"go.ufukty.com/gohandlers/pkg/validator/validate",
+	}
+	if slices.Compare(got, expected) != 0 {
+		t.Errorf("expected %q got %q", expected, got)
+	}
+}
+
+func TestInspect_split(t *testing.T) {
+	type tc struct {
+		description string
+		inspect     func(string) (*Package, error)
+	}
+	tcs := []tc{
+		{"syntax", Dir},
+		{"types", Load},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			p, err := tc.inspect("testdata/split")
+			if err != nil {
+				t.Fatalf("act: %v", err)
+			}
+			pets := p.Handlers[Receiver{"pe", "Pets"}]
+			if create := pets["Create"]; create.RequestType == nil || create.ResponseType == nil {
+				t.Errorf("expected binding types declared in another file to be resolved for Create")
+			}
+			if delete := pets["Delete"]; delete.RequestType != nil {
+				t.Errorf("expected DeleteRequest to be skipped as it is not mentioned in the handler")
+			}
+		})
+	}
+}
+
+func TestBinding_unmentioned(t *testing.T) {
+	src := source{fset: token.NewFileSet(), files: map[string]*ast.File{}}
+	d, err := parser.ParseDir(src.fset, "testdata/split", nil, 0)
+	if err != nil {
+		t.Fatalf("prep: %v", err)
+	}
+	for fn, f := range d["split"].Files {
+		src.files[fn] = f
+	}
+	h := &ast.FuncDecl{Name: ast.NewIdent("Delete"), Body: &ast.BlockStmt{}}
+	b, ds, err := src.binding(h, "DeleteRequest")
+	if err != nil {
+		t.Fatalf("act: %v", err)
+	}
+	if b != nil {
+		t.Errorf("expected no binding type")
+	}
+	if len(ds) != 1 || ds[0].Code != CodeBindingUnmentioned || !strings.Contains(ds[0].Message, "skipping DeleteRequest as it is not mentioned") {
+		t.Errorf("expected diagnostic, got %v", ds)
+	}
+}
+
+func TestInspect_embedded(t *testing.T) {
+	type tc struct {
+		description string
+		inspect     func(string) (*Package, error)
+	}
+	tcs := []tc{
+		{"syntax", Dir},
+		{"types", Load},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			p, err := tc.inspect("testdata/embedded")
+			if err != nil {
+				t.Fatalf("act: %v", err)
+			}
+			bq := p.Handlers[Receiver{"pe", "Pets"}]["List"].RequestType
+			if bq == nil {
+				t.Fatalf("expected request binding type")
+			}
+			expected := BindingTypeParameterSources{
+				Route:  map[string]string{"tid": "Scope.Tenant.Id"},
+				Query:  map[string]string{"limit": "Paging.Limit", "offset": "Paging.Offset"},
+				Header: map[string]string{},
+				Cookie: map[string]string{},
+				Json:   map[string]string{"kind": "Breed"},
+				Form:   map[string]string{},
+				File:   map[string]string{},
+			}
+			if !reflect.DeepEqual(bq.Params, expected) {
+				t.Errorf("expected %v got %v", expected, bq.Params)
+			}
+
+			// the embedded type of the other package is only resolved with
+			// type information, rather than the local type with the same name
+			search := p.Handlers[Receiver{"pe", "Pets"}]["Search"].RequestType
+			if search == nil {
+				t.Fatalf("expected request binding type of Search")
+			}
+			query := map[string]string{}
+			if tc.description == "types" {
+				query = map[string]string{"page": "Paging.Page", "size": "Paging.Size"}
+			}
+			if !maps.Equal(search.Params.Query, query) {
+				t.Errorf("expected query params %v got %v", query, search.Params.Query)
+			}
+		})
+	}
+}
+
+func TestFields_conflict(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), "", `package p
+type ListRequest struct {
+	Owner, Tenant string `+"`query:\"t\"`"+`
+}`, 0)
+	if err != nil {
+		t.Fatalf("prep: %v", err)
+	}
+	ts, _ := findTypeSpec(f, "ListRequest")
+	_, err = source{}.bti("ListRequest", ts)
+	if err == nil || !strings.Contains(err.Error(), `query parameter "t" is bound to both Owner and Tenant`) {
+		t.Errorf("expected conflict error, got %v", err)
+	}
+}
+
+func TestInspect_headers(t *testing.T) {
+	type tc struct {
+		description string
+		inspect     func(string) (*Package, error)
+	}
+	tcs := []tc{
+		{"syntax", Dir},
+		{"types", Load},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			p, err := tc.inspect("testdata/headers")
+			if err != nil {
+				t.Fatalf("act: %v", err)
+			}
+			pets := p.Handlers[Receiver{"pe", "Pets"}]
+			get := pets["Get"].RequestType
+			if get == nil {
+				t.Fatalf("expected request binding type")
+			}
+			expected := map[string]string{"X-Tenant-Id": "Tenant", "X-Tags": "Tags"}
+			if !reflect.DeepEqual(get.Params.Header, expected) {
+				t.Errorf("expected the header names to be canonicalized %v got %v", expected, get.Params.Header)
+			}
+			if slices.Compare(get.InOrder(get.Params.Header), []string{"X-Tenant-Id", "X-Tags"}) != 0 {
+				t.Errorf("expected headers in declaration order, got %v", get.InOrder(get.Params.Header))
+			}
+			del := pets["Delete"].RequestType
+			if del == nil || del.Empty || del.ContainsBody {
+				t.Errorf("expected DeleteRequest to be non-empty without body, got %v", del)
+			}
+		})
+	}
+
+	p, err := Load("testdata/headers")
+	if err != nil {
+		t.Fatalf("act: Load: %v", err)
+	}
+	if !slices.ContainsFunc(p.Diagnostics, func(d Diagnostic) bool {
+		return d.Code == CodeFieldMethods && d.Handler == "Delete" &&
+			strings.Contains(d.Message, "type of the header field DeleteRequest.IfMatch is missing methods: FromHeader, ToHeader, Validate")
+	}) {
+		t.Errorf("expected diagnostic about the IfMatch field, got %v", p.Diagnostics)
+	}
+	if slices.ContainsFunc(p.Diagnostics, func(d Diagnostic) bool { return d.Handler == "Get" }) {
+		t.Errorf("expected no diagnostics for Get, got %v", p.Diagnostics)
+	}
+}
+
+func TestFields_headerConflict(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), "", `package p
+type GetRequest struct {
+	Tenant string `+"`header:\"x-tenant-id\"`"+`
+	Scope  string `+"`header:\"X-Tenant-ID\"`"+`
+}`, 0)
+	if err != nil {
+		t.Fatalf("prep: %v", err)
+	}
+	ts, _ := findTypeSpec(f, "GetRequest")
+	_, err = source{}.bti("GetRequest", ts)
+	if err == nil || !strings.Contains(err.Error(), `header parameter "X-Tenant-Id" is bound to both Tenant and Scope`) {
+		t.Errorf("expected conflict error, got %v", err)
+	}
+}
+
+func TestInspect_cookies(t *testing.T) {
+	type tc struct {
+		description string
+		inspect     func(string) (*Package, error)
+	}
+	tcs := []tc{
+		{"syntax", Dir},
+		{"types", Load},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			p, err := tc.inspect("testdata/cookies")
+			if err != nil {
+				t.Fatalf("act: %v", err)
+			}
+			bq := p.Handlers[Receiver{"se", "Sessions"}]["Refresh"].RequestType
+			if bq == nil {
+				t.Fatalf("expected request binding type")
+			}
+			expected := map[string]string{"session": "Session", "csrf_token": "Csrf"}
+			if !reflect.DeepEqual(bq.Params.Cookie, expected) {
+				t.Errorf("expected %v got %v", expected, bq.Params.Cookie)
+			}
+			if bq.Empty || bq.ContainsBody {
+				t.Errorf("expected RefreshRequest to be non-empty without body")
+			}
+		})
+	}
+
+	p, err := Load("testdata/cookies")
+	if err != nil {
+		t.Fatalf("act: Load: %v", err)
+	}
+	if slices.ContainsFunc(p.Diagnostics, func(d Diagnostic) bool { return d.Code == CodeFieldMethods }) {
+		t.Errorf("expected the basics types to implement the cookie methods, got %v", p.Diagnostics)
+	}
+}
+
+func TestFields_cookieName(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), "", `package p
+type RefreshRequest struct {
+	Session string `+"`cookie:\"my session\"`"+`
+}`, 0)
+	if err != nil {
+		t.Fatalf("prep: %v", err)
+	}
+	ts, _ := findTypeSpec(f, "RefreshRequest")
+	_, err = source{}.bti("RefreshRequest", ts)
+	if err == nil || !strings.Contains(err.Error(), `RefreshRequest: cookie name "my session" of Session`) {
+		t.Errorf("expected invalid cookie name error, got %v", err)
+	}
+}
+
+func TestInspect_responses(t *testing.T) {
+	type tc struct {
+		description string
+		inspect     func(string) (*Package, error)
+	}
+	tcs := []tc{
+		{"syntax", Dir},
+		{"types", Load},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			p, err := tc.inspect("testdata/responses")
+			if err != nil {
+				t.Fatalf("act: %v", err)
+			}
+			create := p.Handlers[Receiver{"pe", "Pets"}]["Create"]
+			bs := create.ResponseType
+			if bs == nil {
+				t.Fatalf("expected response binding type")
+			}
+			if bs.Status != "Status" {
+				t.Errorf("expected the status field, got %q", bs.Status)
+			}
+			if !reflect.DeepEqual(bs.Params.Header, map[string]string{"Location": "Location"}) {
+				t.Errorf("expected the header field, got %v", bs.Params.Header)
+			}
+			if !reflect.DeepEqual(bs.Params.Cookie, map[string]string{"session": "Session"}) {
+				t.Errorf("expected the cookie field, got %v", bs.Params.Cookie)
+			}
+			if create.Status != 201 {
+				t.Errorf("expected the success status code 201, got %d", create.Status)
+			}
+			if !bs.Viewed() || create.RequestType.Viewed() {
+				t.Errorf("expected only the response body to be encoded through a view")
+			}
+			got := map[string]string{}
+			for fp, e := range bs.JsonTypes {
+				got[fp] = types.ExprString(e) + " " + bs.JsonTags[fp]
+			}
+			expected := map[string]string{"Id": "string id", "Born": "time.Time born,omitempty"}
+			if !maps.Equal(got, expected) {
+				t.Errorf("expected the json fields %v got %v", expected, got)
+			}
+			if len(bs.Imports) != 1 || bs.Imports[0].Path.Value != `"time"` {
+				t.Errorf("expected the json fields to need the time package")
+			}
+			get := p.Handlers[Receiver{"pe", "Pets"}]["Get"].ResponseType
+			if get == nil || get.Empty || get.ContainsBody {
+				t.Errorf("expected GetResponse to be non-empty without body, got %v", get)
+			}
+		})
+	}
+
+	p, err := Load("testdata/responses")
+	if err != nil {
+		t.Fatalf("act: Load: %v", err)
+	}
+	expected := []string{
+		"type of the header field GetResponse.Etag is missing methods: FromHeader, ToHeader",
+		"type of the status field GetResponse.Status needs to be int, not uint16",
+	}
+	got := []string{}
+	for _, d := range p.Diagnostics {
+		if d.Code == CodeFieldMethods || d.Code == CodeFieldType {
+			got = append(got, d.Message)
+		}
+	}
+	if slices.Compare(got, expected) != 0 {
+		t.Errorf("expected %q got %q", expected, got)
+	}
+}
+
+func TestFields_statusConflict(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), "", `package p
+type CreateResponse struct {
+	Status, Code int `+"`status:\"\"`"+`
+}`, 0)
+	if err != nil {
+		t.Fatalf("prep: %v", err)
+	}
+	ts, _ := findTypeSpec(f, "CreateResponse")
+	_, err = source{}.bti("CreateResponse", ts)
+	if err == nil || !strings.Contains(err.Error(), "CreateResponse: status code is bound to both Status and Code") {
+		t.Errorf("expected conflict error, got %v", err)
+	}
+}
+
+func TestInspect_uploads(t *testing.T) {
+	type tc struct {
+		description string
+		inspect     func(string) (*Package, error)
+	}
+	tcs := []tc{
+		{"syntax", Dir},
+		{"types", Load},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			p, err := tc.inspect("testdata/uploads")
+			if err != nil {
+				t.Fatalf("act: %v", err)
+			}
+			upload := p.Handlers[Receiver{"pe", "Pets"}]["UploadPhoto"]
+			bq := upload.RequestType
+			if bq == nil {
+				t.Fatalf("expected request binding type")
+			}
+			if !reflect.DeepEqual(bq.Params.File, map[string]string{"photo": "Photo"}) {
+				t.Errorf("expected the file field, got %v", bq.Params.File)
+			}
+			if !bq.ContainsBody || bq.ContentType != "multipart/form-data" || bq.MaxMemory != DefaultMaxMemory {
+				t.Errorf("expected multipart body with the default memory limit, got %q %d", bq.ContentType, bq.MaxMemory)
+			}
+			if upload.Method != "POST" {
+				t.Errorf("expected POST, got %s", upload.Method)
+			}
+			if slices.ContainsFunc(p.Diagnostics, func(d Diagnostic) bool { return d.Code == CodeFieldMethods }) {
+				t.Errorf("expected the basics types to implement the methods, got %v", p.Diagnostics)
+			}
+		})
+	}
+
+	p, err := Conventions{MaxMemory: 1 << 10}.Dir("testdata/uploads")
+	if err != nil {
+		t.Fatalf("act: %v", err)
+	}
+	if m := p.Handlers[Receiver{"pe", "Pets"}]["UploadPhoto"].RequestType.MaxMemory; m != 1<<10 {
+		t.Errorf("expected the memory limit of conventions, got %d", m)
+	}
+}
+
+func TestFields_jsonAndFile(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), "", `package p
+type UploadRequest struct {
+	Name  string `+"`json:\"name\"`"+`
+	Photo File   `+"`file:\"photo\"`"+`
+}`, 0)
+	if err != nil {
+		t.Fatalf("prep: %v", err)
+	}
+	ts, _ := findTypeSpec(f, "UploadRequest")
+	_, err = source{}.bti("UploadRequest", ts)
+	if err == nil || !strings.Contains(err.Error(), "both json {Name} and file {Photo} tagged fields found") {
+		t.Errorf("expected content type error, got %v", err)
+	}
+}
+
+func TestInspect_streams(t *testing.T) {
+	type tc struct {
+		description string
+		inspect     func(string) (*Package, error)
+	}
+	tcs := []tc{
+		{"syntax", Dir},
+		{"types", Load},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			p, err := tc.inspect("testdata/streams")
+			if err != nil {
+				t.Fatalf("act: %v", err)
+			}
+			feeds := p.Handlers[Receiver{"fe", "Feeds"}]
+			type expected struct {
+				body, contentType, method string
+			}
+			for h, e := range map[string]expected{
+				"Import":  {"Items", "application/x-ndjson", "POST"},
+				"Upload":  {"Blob", "application/octet-stream", "POST"},
+				"Replace": {"Blob", "image/png", "PUT"},
+			} {
+				bq := feeds[h].RequestType
+				if bq == nil {
+					t.Fatalf("%s: expected request binding type", h)
+				}
+				got := expected{bq.Body, bq.ContentType, feeds[h].Method}
+				if got != e || !bq.ContainsBody {
+					t.Errorf("%s: expected %v got %v", h, e, got)
+				}
+			}
+		})
+	}
+
+	p, err := Load("testdata/streams")
+	if err != nil {
+		t.Fatalf("act: Load: %v", err)
+	}
+	got := []string{}
+	for _, d := range p.Diagnostics {
+		if d.Code == CodeFieldType {
+			got = append(got, d.Message)
+		}
+	}
+	expected := []string{"type of the body field ReplaceRequest.Blob needs to be io.ReadCloser or io.Reader, not []byte"}
+	if slices.Compare(got, expected) != 0 {
+		t.Errorf("expected %q got %q", expected, got)
+	}
+}
+
+func TestFields_body(t *testing.T) {
+	tcs := map[string]string{
+		"Items io.Reader `body:\"text/\"`":                           `ImportRequest: content type "text/" of Items`,
+		"Items io.Reader `body:\"\"`\n\tMore io.Reader `body:\"\"`":  "ImportRequest: raw body is bound to both Items and More",
+		"Items io.Reader `body:\"\"`\n\tName string `json:\"name\"`": "both the raw body Items and the body fields {Name} found",
+		"Items io.Reader `body:\"\"`\n\tName string `form:\"name\"`": "both the raw body Items and the body fields {Name} found",
+	}
+	for fields, msg := range tcs {
+		f, err := parser.ParseFile(token.NewFileSet(), "", "package p\ntype ImportRequest struct {\n\t"+fields+"\n}", 0)
+		if err != nil {
+			t.Fatalf("prep: %v", err)
+		}
+		ts, _ := findTypeSpec(f, "ImportRequest")
+		_, err = source{}.bti("ImportRequest", ts)
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("%s: expected error %q, got %v", fields, msg, err)
+		}
+	}
+}
+
+func TestInspect_arrays(t *testing.T) {
+	type tc struct {
+		description string
+		inspect     func(string) (*Package, error)
+	}
+	tcs := []tc{
+		{"syntax", Dir},
+		{"types", Load},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			p, err := tc.inspect("testdata/arrays")
+			if err != nil {
+				t.Fatalf("act: %v", err)
+			}
+			bq := p.Handlers[Receiver{"pe", "Pets"}]["List"].RequestType
+			if bq == nil {
+				t.Fatalf("expected request binding type")
+			}
+			expected := map[string]string{"tag": "Tags", "colors": "Colors", "sort": "Sort", "filter": "Filter"}
+			if !maps.Equal(bq.Params.Query, expected) {
+				t.Errorf("expected query params %v got %v", expected, bq.Params.Query)
+			}
+			expected = map[string]string{"tag": "repeat", "colors": "comma"}
+			if !maps.Equal(bq.Arrays, expected) {
+				t.Errorf("expected arrays %v got %v", expected, bq.Arrays)
+			}
+			if !maps.Equal(bq.Objects, map[string]bool{"filter": true}) {
+				t.Errorf("expected filter as the only object, got %v", bq.Objects)
+			}
+		})
+	}
+
+	p, err := Load("testdata/arrays")
+	if err != nil {
+		t.Fatalf("act: Load: %v", err)
+	}
+	got := []string{}
+	for _, d := range p.Diagnostics {
+		if d.Code == CodeFieldMethods {
+			got = append(got, d.Message)
+		}
+	}
+	expected := []string{
+		"type of the query field SearchRequest.Filter is missing methods: FromQueryObject, ToQueryObject",
+		"type of the query field SearchRequest.Owner is missing methods: FromQuery, ToQuery",
+		"type of the query field SearchRequest.Tags is missing methods: FromQueryValues, ToQueryValues",
+	}
+	if slices.Compare(got, expected) != 0 {
+		t.Errorf("expected %q got %q", expected, got)
+	}
+}
+
+func TestFields_queryOption(t *testing.T) {
+	tcs := map[string]string{
+		"tag,csv":          `ListRequest: unknown option "csv" in the query tag of Tags`,
+		"tag,deep,repeat":  `ListRequest: the query tag of Tags can only have one of the deep, repeat and comma options`,
+		"tag,repeat,comma": `ListRequest: the query tag of Tags can only have one of the deep, repeat and comma options`,
+	}
+	for tag, msg := range tcs {
+		t.Run(tag, func(t *testing.T) {
+			f, err := parser.ParseFile(token.NewFileSet(), "", fmt.Sprintf("package p\ntype ListRequest struct {\n\tTags []string `query:%q`\n}", tag), 0)
+			if err != nil {
+				t.Fatalf("prep: %v", err)
+			}
+			ts, _ := findTypeSpec(f, "ListRequest")
+			_, err = source{}.bti("ListRequest", ts)
+			if err == nil || err.Error() != msg {
+				t.Errorf("expected error %q, got %v", msg, err)
+			}
+		})
+	}
+}
+
+func TestInspect_options(t *testing.T) {
+	type tc struct {
+		description string
+		inspect     func(string) (*Package, error)
+	}
+	tcs := []tc{
+		{"syntax", Dir},
+		{"types", Load},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			p, err := tc.inspect("testdata/options")
+			if err != nil {
+				t.Fatalf("act: %v", err)
+			}
+			bq := p.Handlers[Receiver{"pe", "Pets"}]["Search"].RequestType
+			if bq == nil {
+				t.Fatalf("expected request binding type")
+			}
+			expected := map[string]string{"owner": "Owner", "colors": "Colors"}
+			if !maps.Equal(bq.Params.Query, expected) {
+				t.Errorf("expected query params %v got %v", expected, bq.Params.Query)
+			}
+			expected = map[string]string{"X-Tenant": "Tenant"}
+			if !maps.Equal(bq.Params.Header, expected) {
+				t.Errorf("expected headers %v got %v", expected, bq.Params.Header)
+			}
+			expected = map[string]string{"name": "Name", "Tags": "Tags", "-": "Dash"}
+			if !maps.Equal(bq.Params.Json, expected) {
+				t.Errorf("expected json params %v got %v", expected, bq.Params.Json)
+			}
+			if !maps.Equal(bq.Required, map[string]bool{"Owner": true, "Tenant": true}) {
+				t.Errorf("expected Owner and Tenant to be required, got %v", bq.Required)
+			}
+			expected = map[string]string{"Colors": "black,white"}
+			if !maps.Equal(bq.Defaults, expected) {
+				t.Errorf("expected defaults %v got %v", expected, bq.Defaults)
+			}
+			if !maps.Equal(bq.Arrays, map[string]string{"colors": "comma"}) {
+				t.Errorf("expected colors as the only array, got %v", bq.Arrays)
+			}
+			declared := []string{"Owner", "Colors", "Tenant", "Name", "Tags", "Dash"}
+			if !slices.Equal(bq.Declared, declared) {
+				t.Errorf("expected declared fields %v got %v", declared, bq.Declared)
+			}
+		})
+	}
+}
+
+func TestFields_options(t *testing.T) {
+	tcs := map[string]string{
+		"Id string `cookie:\"id,required\"`":              `SearchRequest: unknown option "required" in the cookie tag of Id`,
+		"Id string `route:\"id,deep\"`":                   `SearchRequest: unknown option "deep" in the route tag of Id`,
+		"Id string `query:\"id,required,default=1\"`":     "SearchRequest: Id is both required and has a default",
+		"Filter string `query:\"filter,deep,default=a\"`": "SearchRequest: deep object Filter can't have a default",
+		"Id string `form:\"id,,required\"`":               `SearchRequest: unknown option "" in the form tag of Id`,
+		"Id string `json:\"id\" query:\"id,omitempty\"`":  `SearchRequest: unknown option "omitempty" in the query tag of Id`,
+	}
+	for fields, msg := range tcs {
+		f, err := parser.ParseFile(token.NewFileSet(), "", "package p\ntype SearchRequest struct {\n\t"+fields+"\n}", 0)
+		if err != nil {
+			t.Fatalf("prep: %v", err)
+		}
+		ts, _ := findTypeSpec(f, "SearchRequest")
+		_, err = source{}.bti("SearchRequest", ts)
+		if err == nil || err.Error() != msg {
+			t.Errorf("%s: expected error %q, got %v", fields, msg, err)
+		}
+	}
+}
+
+func TestInspect_signatures(t *testing.T) {
+	type tc struct {
+		description string
+		inspect     func(string) (*Package, error)
+		expected    []string
+	}
+	tcs := []tc{
+		{"syntax", Dir, []string{"Aliased", "Blank", "Dotted", "Renamed", "Unnamed"}},
+		{"types", Load, []string{"Aliased", "Blank", "Dotted", "Renamed", "TypeAliased", "Unnamed"}},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			p, err := tc.inspect("testdata/signatures")
+			if err != nil {
+				t.Fatalf("act: %v", err)
+			}
+			got := slices.Sorted(maps.Keys(p.Handlers[Receiver{"pe", "Pets"}]))
+			if slices.Compare(got, tc.expected) != 0 {
+				t.Errorf("expected %q got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestInspect_factories(t *testing.T) {
+	type tc struct {
+		description string
+		inspect     func(string) (*Package, error)
+	}
+	tcs := []tc{
+		{"syntax", Dir},
+		{"types", Load},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			p, err := tc.inspect("testdata/factories")
+			if err != nil {
+				t.Fatalf("act: %v", err)
+			}
+			refs := map[string]string{}
+			for recv, infos := range p.Handlers {
+				for hn, i := range infos {
+					refs[recv.Type+"."+hn] = types.ExprString(i.Ref)
+				}
+			}
+			expected := map[string]string{
+				"Api.Health":        "ap.Health().ServeHTTP",
+				"Api.Status":        "ap.Status",
+				"Api.Upload":        "ap.Upload(uploadLimit, uploadTimeout)",
+				"Download.Download": "do.ServeHTTP",
+			}
+			if !maps.Equal(refs, expected) {
+				t.Errorf("expected %v got %v", expected, refs)
+			}
+			upload := p.Handlers[Receiver{"ap", "Api"}]["Upload"]
+			if upload.RequestType == nil {
+				t.Errorf("expected the request binding type of factory")
+			}
+			if len(upload.Imports) != 1 || upload.Imports[0].Path.Value != `"time"` {
+				t.Errorf("expected the factory to need the time package")
+			}
+			if p.Handlers[Receiver{"do", "Download"}]["Download"].RequestType == nil {
+				t.Errorf("expected the request binding type of handler type")
+			}
+			variadic := false
+			for _, d := range p.Diagnostics {
+				if d.Code == CodeFactoryVariadic {
+					variadic = d.Handler == "Search" && d.Severity == Error
+				}
+			}
+			if !variadic {
+				t.Errorf("expected the variadic factory to be reported")
+			}
+			undirected := []string{}
+			for _, d := range p.Diagnostics {
+				if d.Code == CodeHandlerUndirected && d.Severity == Notice {
+					undirected = append(undirected, d.Handler)
+				}
+			}
+			slices.Sort(undirected)
+			if expected := []string{"Mux", "Recoverer"}; !slices.Equal(undirected, expected) {
+				t.Errorf("expected the undirected handlers %v to be reported, got %v", expected, undirected)
+			}
+		})
+	}
+}
+
+func TestInspect_generic(t *testing.T) {
+	type tc struct {
+		description string
+		inspect     func(string) (*Package, error)
+	}
+	tcs := []tc{
+		{"syntax", Dir},
+		{"types", Load},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			p, err := tc.inspect("testdata/generic")
+			if err != nil {
+				t.Fatalf("act: %v", err)
+			}
+			recvs := map[string]string{}
+			for recv, infos := range p.Handlers {
+				for hn, i := range infos {
+					recvs[recv.Type+"."+hn] = types.ExprString(i.Recv)
+				}
+			}
+			expected := map[string]string{
+				"Store.Get":  "*Store[T]",
+				"Store.List": "*Store[T]",
+				"Pair.Get":   "Pair[K, V]",
+				"Pets.Get":   "*Pets",
+				"Pets.List":  "Pets",
+				"Tags.List":  "Tags",
+			}
+			if !maps.Equal(recvs, expected) {
+				t.Errorf("expected %v got %v", expected, recvs)
+			}
+		})
+	}
+}
+
+func TestInspect_directives(t *testing.T) {
+	type tc struct {
+		description string
+		inspect     func(string) (*Package, error)
+	}
+	tcs := []tc{
+		{"syntax", Dir},
+		{"types", Load},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			p, err := tc.inspect("testdata/directives")
+			if err != nil {
+				t.Fatalf("act: %v", err)
+			}
+			users := p.Handlers[Receiver{"us", "Users"}]
+			if get := users["Get"]; get.RequestType == nil || get.RequestType.Typename != "UserId" || get.ResponseType == nil || get.ResponseType.Typename != "User" {
+				t.Errorf("expected the binding types of Get to be resolved from directives")
+			}
+			create := users["Create"].RequestType
+			if create == nil {
+				t.Fatalf("expected the imported request binding type of Create")
+			}
+			if create.Local() || create.Package != "go.ufukty.com/gohandlers/pkg/inspects/testdata/directives/users" || create.PackageName != "users" {
+				t.Errorf("unexpected package of imported binding type: %q %q", create.Package, create.PackageName)
+			}
+			if _, ok := create.Params.Json["name"]; !ok {
+				t.Errorf("expected the fields of imported binding type")
+			}
+			find, ok := users["Find"]
+			if !ok {
+				t.Fatalf("expected Lookup to be renamed as Find")
+			}
+			if types.ExprString(find.Ref) != "us.Lookup" || find.Path != "/find" || find.Status != 202 || slices.Compare(find.Tags, []string{"users", "search"}) != 0 {
+				t.Errorf("unexpected info for Find: %s %s %d %v", types.ExprString(find.Ref), find.Path, find.Status, find.Tags)
+			}
+			if users["Visit"].RequestType != nil {
+				t.Errorf("expected the unknown binding type to be skipped")
+			}
+			codes := map[string]string{}
+			for _, d := range p.Diagnostics {
+				if d.Severity > Notice {
+					codes[d.Handler] = d.Code
+				}
+			}
+			expected := map[string]string{
+				"Get":    CodeBindingShared,
+				"Visit":  CodeBindingUnresolved,
+				"Search": CodeDirective,
+			}
+			if tc.description == "types" {
+				expected["Create"] = CodeBindingImported
+			}
+			if !maps.Equal(codes, expected) {
+				t.Errorf("expected %v got %v", expected, codes)
+			}
+		})
+	}
+}
+
+func TestInspect_prefix(t *testing.T) {
+	type tc struct {
+		description string
+		inspect     func(string) (*Package, error)
+	}
+	tcs := []tc{
+		{"syntax", Dir},
+		{"types", Load},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			p, err := tc.inspect("testdata/prefix")
+			if err != nil {
+				t.Fatalf("act: %v", err)
+			}
+			type expected struct {
+				path string
+				tags []string
+			}
+			expecteds := map[Receiver]map[string]expected{
+				{"pe", "Pets"}: {
+					"Get":    {"/pets/{id}", []string{"pets"}},
+					"List":   {"/pets/list", []string{"pets"}},
+					"Delete": {"/pets/delete", []string{"pets", "admin"}},
+				},
+				{"ow", "Owners"}: {
+					"Visit": {"/owners/{oid}/visit/{id}", nil},
+				},
+				{"br", "Broken"}: {
+					"Get": {"/get", nil},
+				},
+			}
+			for recv, hs := range expecteds {
+				for h, e := range hs {
+					i, ok := p.Handlers[recv][h]
+					if !ok {
+						t.Errorf("expected %s.%s", recv.Type, h)
+						continue
+					}
+					if i.Path != e.path {
+						t.Errorf("%s.%s: expected path %q got %q", recv.Type, h, e.path, i.Path)
+					}
+					if slices.Compare(i.Tags, e.tags) != 0 {
+						t.Errorf("%s.%s: expected tags %v got %v", recv.Type, h, e.tags, i.Tags)
+					}
+				}
+			}
+			found := false
+			for _, d := range p.Diagnostics {
+				if d.Code == CodeDirective && d.Handler == "Broken" && d.Message == "gh:list is only applicable to handlers" {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected a diagnostic for the handler directive on type: %v", p.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestParsePattern(t *testing.T) {
+	type tc struct {
+		input     string
+		host      string
+		wildcards []string
+		template  string
+		subtree   bool
+		anchored  bool
+	}
+	tcs := []tc{
+		{"/pets", "", []string{}, "/pets", false, false},
+		{"/pets/{id}", "", []string{"id"}, "/pets/{id}", false, false},
+		{"/files/{path...}", "", []string{"path"}, "/files/{path...}", true, false},
+		{"/pets/", "", []string{}, "/pets/", true, false},
+		{"/{$}", "", []string{}, "/", false, true},
+		{"/pets/{id}/{$}", "", []string{"id"}, "/pets/{id}/", false, true},
+		{"api.example.com/pets/{id}", "api.example.com", []string{"id"}, "/pets/{id}", false, false},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.input, func(t *testing.T) {
+			p, err := ParsePattern(tc.input)
+			if err != nil {
+				t.Fatalf("act: %v", err)
+			}
+			if p.Host != tc.host {
+				t.Errorf("host: expected %q got %q", tc.host, p.Host)
+			}
+			if slices.Compare(p.Wildcards(), tc.wildcards) != 0 {
+				t.Errorf("wildcards: expected %v got %v", tc.wildcards, p.Wildcards())
+			}
+			if p.Template() != tc.template {
+				t.Errorf("template: expected %q got %q", tc.template, p.Template())
+			}
+			if p.Subtree() != tc.subtree || p.Anchored() != tc.anchored {
+				t.Errorf("expected subtree %t and anchored %t", tc.subtree, tc.anchored)
+			}
+		})
+	}
+}
+
+func TestParsePattern_invalid(t *testing.T) {
+	tcs := []string{
+		"",
+		"pets",
+		"{host}/pets",
+		"/pets/../owners",
+		"/pets//{id}",
+		"/pets/id{id}",
+		"/pets/{id",
+		"/pets/{$}/photos",
+		"/files/{path...}/meta",
+		"/pets/{}",
+		"/pets/{1d}",
+		"/pets/{id}/photos/{id}",
+		"/pets/%zz",
+	}
+	for _, tc := range tcs {
+		t.Run(tc, func(t *testing.T) {
+			if _, err := ParsePattern(tc); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestInspect_patterns(t *testing.T) {
+	type tc struct {
+		description string
+		inspect     func(string) (*Package, error)
+	}
+	tcs := []tc{
+		{"syntax", Dir},
+		{"types", Load},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			p, err := tc.inspect("testdata/patterns")
+			if err != nil {
+				t.Fatalf("act: %v", err)
+			}
+			files := p.Handlers[Receiver{"fi", "Files"}]
+			expected := map[string]string{
+				"Download": "/files/{path...}",
+				"Index":    "/{$}",
+				"Assets":   "static.example.com/assets/",
+			}
+			for h, path := range expected {
+				if files[h].Path != path {
+					t.Errorf("%s: expected path %q got %q", h, path, files[h].Path)
+				}
+			}
+			if files["Assets"].Pattern == nil || files["Assets"].Pattern.Host != "static.example.com" {
+				t.Errorf("expected the host of Assets")
+			}
+			if _, ok := files["Browse"]; ok {
+				t.Errorf("expected Browse to be skipped as the route parameter can't be appended")
+			}
+			if _, ok := p.Handlers[Receiver{"di", "Dirs"}]["Get"]; ok {
+				t.Errorf("expected Dirs.Get to be skipped for the duplicate wildcard")
+			}
+			invalid := []string{}
+			for _, d := range p.Diagnostics {
+				if d.Code == CodePathInvalid {
+					invalid = append(invalid, d.Handler)
+				}
+			}
+			slices.Sort(invalid)
+			if slices.Compare(invalid, []string{"Browse", "Get"}) != 0 {
+				t.Errorf("expected invalid paths for Browse and Get, got %v", invalid)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	type tc struct {
+		m1, p1, m2, p2 string
+		expected       relationship
+	}
+	tcs := []tc{
+		{"GET", "/pets", "GET", "/pets", equivalent},
+		{"GET", "/pets/{id}", "GET", "/pets/{name}", equivalent},
+		{"GET", "/pets/{id}", "POST", "/pets/{id}", disjoint},
+		{"GET", "/pets/{id}", "GET", "/pets/new", moreGeneral},
+		{"GET", "/pets/new", "GET", "/pets/{id}", moreSpecific},
+		{"GET", "/pets/", "GET", "/pets/{id}", moreGeneral},
+		{"GET", "/files/{path...}", "GET", "/files/", equivalent},
+		{"GET", "/pets/{$}", "GET", "/pets/{id}", disjoint},
+		{"GET", "/pets/{$}", "GET", "/pets/", moreSpecific},
+		{"GET", "/{owner}/pets", "GET", "/owners/{id}", overlaps},
+		{"GET", "/pets/{id}", "HEAD", "/pets/{id}", moreGeneral},
+		{"GET", "/pets/new", "HEAD", "/pets/{id}", overlaps},
+		{"GET", "/pets", "GET", "/pets/{id}", disjoint},
+		{"GET", "api.example.com/pets", "GET", "/pets", disjoint},
+	}
+	for _, tc := range tcs {
+		t.Run(fmt.Sprintf("%s %s vs %s %s", tc.m1, tc.p1, tc.m2, tc.p2), func(t *testing.T) {
+			p1, err := ParsePattern(tc.p1)
+			if err != nil {
+				t.Fatalf("prep: %v", err)
+			}
+			p2, err := ParsePattern(tc.p2)
+			if err != nil {
+				t.Fatalf("prep: %v", err)
+			}
+			if got := compare(tc.m1, p1, tc.m2, p2); got != tc.expected {
+				t.Errorf("expected %d got %d", tc.expected, got)
+			}
+			if got := compare(tc.m2, p2, tc.m1, p1); got != inverse(tc.expected) {
+				t.Errorf("inverse: expected %d got %d", inverse(tc.expected), got)
+			}
+			panicked := func() (panicked bool) {
+				defer func() { panicked = recover() != nil }()
+				mux := http.NewServeMux()
+				mux.HandleFunc(tc.m1+" "+tc.p1, func(w http.ResponseWriter, r *http.Request) {})
+				mux.HandleFunc(tc.m2+" "+tc.p2, func(w http.ResponseWriter, r *http.Request) {})
+				return
+			}()
+			if conflicting := tc.expected == equivalent || tc.expected == overlaps; panicked != conflicting {
+				t.Errorf("expected ServeMux to panic: %t", conflicting)
+			}
+		})
+	}
+}
+
+func TestInspect_conflicts(t *testing.T) {
+	p, err := Dir("testdata/conflicts")
+	if err != nil {
+		t.Fatalf("act: %v", err)
+	}
+	got := []string{}
+	for _, d := range p.Diagnostics {
+		if d.Code == CodeRouteConflict {
+			if d.Severity != Error {
+				t.Errorf("expected conflicts to be errors")
+			}
+			got = append(got, d.Message)
+		}
+	}
+	slices.Sort(got)
+	expected := []string{
+		"GET /pets/new overlaps with HEAD /pets/{id} of Pets.Head, as neither is more specific",
+		"GET /pets/{id} duplicates the route of Pets.Get",
+		"GET /pets/{id} overlaps with GET /{owner}/pets of Owners.Pets, as neither is more specific",
+		"GET /pets/{id} overlaps with GET /{owner}/pets of Owners.Pets, as neither is more specific",
+		"GET /{owner}/pets overlaps with HEAD /owners/{id} of Owners.Head, as neither is more specific",
+		"HEAD /pets/{id} overlaps with GET /{owner}/pets of Owners.Pets, as neither is more specific",
+	}
+	if slices.Compare(got, expected) != 0 {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestConventions(t *testing.T) {
+	c := Conventions{
+		Verbs:       map[string]string{"List": "GET", "Fetch": "GET", "Make": "POST"},
+		ContentType: "application/vnd.api+json",
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("prep: %v", err)
+	}
+	for name, expected := range map[string]string{"ListPets": "GET", "FetchPet": "GET", "MakePet": "POST", "CreatePet": ""} {
+		if got := c.decideMethodFromHandlerName(&ast.FuncDecl{Name: ast.NewIdent(name)}); got != expected {
+			t.Errorf("%s: expected %q got %q", name, expected, got)
+		}
+	}
+
+	p, err := c.Dir("testdata/directives")
+	if err != nil {
+		t.Fatalf("act: %v", err)
+	}
+	if ct := p.Handlers[Receiver{"us", "Users"}]["Get"].ResponseType.ContentType; ct != c.ContentType {
+		t.Errorf("expected the content type of json bodies to be replaced, got %q", ct)
+	}
+
+	invalid := []Conventions{
+		{Verbs: map[string]string{"list": "GET"}},
+		{Verbs: map[string]string{"List": "FETCH"}},
+		{Paths: "camel"},
+		{ContentType: "json;"},
+	}
+	for _, c := range invalid {
+		if err := c.Validate(); err == nil {
+			t.Errorf("expected an error for %v", c)
+		}
+	}
+}
+
+func TestConventions_paths(t *testing.T) {
+	type handler struct {
+		recv Receiver
+		name string
+	}
+	var (
+		photo  = handler{Receiver{"ow", "Owners"}, "GetPhoto"}
+		create = handler{Receiver{"ow", "Owners"}, "Create"}
+		delete = handler{Receiver{"pe", "Pets"}, "Delete"}
+		get    = handler{Receiver{"", ""}, "Get"}
+	)
+	tcs := map[string]map[handler]string{
+		"": {
+			photo:  "/get-photo/{owner}/{pet}/{id}",
+			create: "/create",
+			delete: "/pets/delete",
+			get:    "/get",
+		},
+		"snake": {
+			photo:  "/get_photo/{owner}/{pet}/{id}",
+			create: "/create",
+			delete: "/pets/delete",
+			get:    "/get",
+		},
+		"resource": {
+			photo:  "/owners/photo/{owner}/{pet}/{id}",
+			create: "/owners",
+			delete: "/pets",
+			get:    "/get",
+		},
+		"verb-stripped": {
+			photo:  "/photo/{owner}/{pet}/{id}",
+			create: "/create",
+			delete: "/pets/delete",
+			get:    "/get",
+		},
+	}
+	for style, expected := range tcs {
+		t.Run(cmp.Or(style, "default"), func(t *testing.T) {
+			c := Conventions{Paths: style}
+			if err := c.Validate(); err != nil {
+				t.Fatalf("prep: %v", err)
+			}
+			p, err := c.Dir("testdata/styles")
+			if err != nil {
+				t.Fatalf("act: %v", err)
+			}
+			for h, path := range expected {
+				i, ok := p.Handlers[h.recv][h.name]
+				if !ok {
+					t.Errorf("expected %s.%s", h.recv.Type, h.name)
+					continue
+				}
+				if i.Path != path {
+					t.Errorf("%s.%s: expected %q got %q", h.recv.Type, h.name, path, i.Path)
+				}
+			}
+		})
+	}
+}
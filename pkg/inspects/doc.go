@@ -0,0 +1,270 @@
+package inspects
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var methods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodConnect,
+	http.MethodOptions,
+	http.MethodTrace,
+}
+
+type Mode string
+
+func (m Mode) Ignore() bool {
+	return m == "ignore"
+}
+
+func (m Mode) ToList() bool {
+	return m != "ignore" && m == "list"
+}
+
+func (m Mode) ParseBindings() bool {
+	return m != "ignore" && m != "list"
+}
+
+type Doc struct {
+	Method, Path      string
+	Mode              Mode
+	Request, Response string   // binding types specified with gh:request and gh:response
+	Name              string   // replaces the handler name in listers, clients and binding type names
+	Status            int      // the status code of successful responses
+	Tags              []string // for grouping handlers in the exported files
+	Prefix            string   // prepended to the paths of handlers of a receiver type
+
+	issues []issue
+}
+
+// directed reports if the doc comment has any directives, including the
+// malformed ones
+func (doc Doc) directed() bool {
+	return doc.Method != "" || doc.Path != "" || doc.Mode != "" || doc.Request != "" || doc.Response != "" ||
+		doc.Name != "" || doc.Status != 0 || len(doc.Tags) > 0 || doc.Prefix != "" || len(doc.issues) > 0
+}
+
+// issue is a malformed or unknown directive
+type issue struct {
+	pos token.Pos
+	msg string
+}
+
+func (doc *Doc) complain(pos token.Pos, format string, a ...any) {
+	doc.issues = append(doc.issues, issue{pos, fmt.Sprintf(format, a...)})
+}
+
+// directives with the number of arguments they take; -1 for one or more
+var directives = map[string]int{
+	"gh:ignore":   0,
+	"gh:list":     0,
+	"gh:method":   1,
+	"gh:path":     1,
+	"gh:name":     1,
+	"gh:status":   1,
+	"gh:tag":      -1,
+	"gh:request":  1,
+	"gh:response": 1,
+	"gh:prefix":   1,
+}
+
+// directives that are placed on the receiver type declarations
+var typeDirectives = []string{"gh:prefix", "gh:tag"}
+
+// set assigns v to the field unless it is already assigned a different
+// value. The directive is empty for the short form of method and path.
+func (doc *Doc) set(pos token.Pos, directive string, field *string, v string) {
+	if *field != "" && *field != v {
+		doc.complain(pos, "%s conflicts with the earlier %q", strings.TrimSpace(directive+" "+v), *field)
+		return
+	}
+	*field = v
+}
+
+func (doc *Doc) directive(pos token.Pos, d string, args []string) {
+	n, ok := directives[d]
+	if !ok {
+		doc.complain(pos, "unknown directive %s", d)
+		return
+	}
+	switch {
+	case n == 0 && len(args) > 0:
+		doc.complain(pos, "%s takes no arguments", d)
+		return
+	case n == 1 && len(args) != 1:
+		doc.complain(pos, "%s takes one argument", d)
+		return
+	case n == -1 && len(args) == 0:
+		doc.complain(pos, "%s takes at least one argument", d)
+		return
+	}
+
+	switch d {
+	case "gh:ignore", "gh:list":
+		if doc.Mode != "" && "gh:"+string(doc.Mode) != d {
+			doc.complain(pos, "%s conflicts with the earlier gh:%s", d, doc.Mode)
+			return
+		}
+		doc.Mode = Mode(strings.TrimPrefix(d, "gh:"))
+	case "gh:method":
+		doc.method(pos, d, args[0])
+	case "gh:path":
+		doc.path(pos, d, args[0])
+	case "gh:name":
+		if !token.IsIdentifier(args[0]) {
+			doc.complain(pos, "%s %s is not a valid identifier", d, args[0])
+			return
+		}
+		doc.set(pos, d, &doc.Name, args[0])
+	case "gh:status":
+		s, err := strconv.Atoi(args[0])
+		if err != nil || s < 100 || s > 599 {
+			doc.complain(pos, "%s %s is not a valid status code", d, args[0])
+			return
+		}
+		if doc.Status != 0 && doc.Status != s {
+			doc.complain(pos, "%s %d conflicts with the earlier %d", d, s, doc.Status)
+			return
+		}
+		doc.Status = s
+	case "gh:tag":
+		for _, t := range args {
+			if !slices.Contains(doc.Tags, t) {
+				doc.Tags = append(doc.Tags, t)
+			}
+		}
+	case "gh:request":
+		doc.set(pos, d, &doc.Request, args[0])
+	case "gh:response":
+		doc.set(pos, d, &doc.Response, args[0])
+	case "gh:prefix":
+		if !strings.HasPrefix(args[0], "/") {
+			doc.complain(pos, "%s %s doesn't start with a slash", d, args[0])
+			return
+		}
+		if strings.HasSuffix(args[0], "/") {
+			doc.complain(pos, "%s %s ends with a slash", d, args[0])
+			return
+		}
+		p, err := ParsePattern(args[0])
+		if err != nil {
+			doc.complain(pos, "%s %s: %s", d, args[0], err)
+			return
+		}
+		if p.Subtree() || p.Anchored() {
+			doc.complain(pos, "%s %s can't end the path", d, args[0])
+			return
+		}
+		doc.set(pos, d, &doc.Prefix, args[0])
+	}
+}
+
+func (doc *Doc) method(pos token.Pos, d, m string) {
+	if !slices.Contains(methods, m) {
+		doc.complain(pos, "unknown method %s", m)
+		return
+	}
+	doc.set(pos, d, &doc.Method, m)
+}
+
+func (doc *Doc) path(pos token.Pos, d, p string) {
+	if _, err := ParsePattern(p); err != nil {
+		doc.complain(pos, "%s: %s", strings.TrimSpace(d+" "+p), err)
+		return
+	}
+	doc.set(pos, d, &doc.Path, p)
+}
+
+// lines returns the lines of a comment without the comment markers
+func lines(c string) []string {
+	if strings.HasPrefix(c, "/*") {
+		ls := strings.Split(strings.TrimSuffix(strings.TrimPrefix(c, "/*"), "*/"), "\n")
+		for i, l := range ls {
+			ls[i] = strings.TrimPrefix(strings.TrimSpace(l), "*")
+		}
+		return ls
+	}
+	return []string{strings.TrimPrefix(c, "//")}
+}
+
+// looks like a method but isn't one, eg. "PSOT" in "PSOT /pets"
+var uppercase = regexp.MustCompile(`^[A-Z]+$`)
+
+// a host qualified pattern, eg. "api.example.com/pets"
+var hosted = regexp.MustCompile(`^[a-zA-Z0-9-]+(\.[a-zA-Z0-9-]+)+(:[0-9]+)?/`)
+
+// pathlike reports whether the word is written as a pattern in the
+// short form, either starting with a slash or a host name with dots
+func pathlike(w string) bool {
+	return strings.HasPrefix(w, "/") || hosted.MatchString(w)
+}
+
+// parseDoc reads the directives in the doc comment, one per line. Beside
+// the gh: prefixed directives, the method and path can be specified in
+// short as in "GET", "/pets" or "GET /pets".
+func parseDoc(fd *ast.FuncDecl) Doc {
+	doc := Doc{}
+	if fd.Doc == nil {
+		return doc
+	}
+	for _, c := range fd.Doc.List {
+		for _, line := range lines(c.Text) {
+			words := strings.Fields(line)
+			switch {
+			case len(words) == 0:
+			case words[0] == "gh:prefix":
+				doc.complain(c.Pos(), "%s is only applicable to receiver types", words[0])
+			case strings.HasPrefix(words[0], "gh:"):
+				doc.directive(c.Pos(), words[0], words[1:])
+			case slices.Contains(methods, words[0]) && (len(words) == 1 || pathlike(words[1])):
+				doc.method(c.Pos(), "", words[0])
+				if len(words) > 1 {
+					doc.path(c.Pos(), "", words[1])
+				}
+			case len(words) == 1 && pathlike(words[0]):
+				doc.path(c.Pos(), "", words[0])
+			case uppercase.MatchString(words[0]) && len(words) > 1 && pathlike(words[1]):
+				doc.complain(c.Pos(), "unknown method %s", words[0])
+			}
+		}
+	}
+	return doc
+}
+
+// parseTypeDoc reads the directives shared by the handlers of a receiver
+// type. Lines without the gh: prefix are prose for types.
+func parseTypeDoc(cg *ast.CommentGroup) Doc {
+	doc := Doc{}
+	if cg == nil {
+		return doc
+	}
+	for _, c := range cg.List {
+		for _, line := range lines(c.Text) {
+			words := strings.Fields(line)
+			switch {
+			case len(words) == 0 || !strings.HasPrefix(words[0], "gh:"):
+			case slices.Contains(typeDirectives, words[0]):
+				doc.directive(c.Pos(), words[0], words[1:])
+			default:
+				if _, ok := directives[words[0]]; ok {
+					doc.complain(c.Pos(), "%s is only applicable to handlers", words[0])
+				} else {
+					doc.complain(c.Pos(), "unknown directive %s", words[0])
+				}
+			}
+		}
+	}
+	return doc
+}
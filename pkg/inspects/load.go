@@ -0,0 +1,329 @@
+package inspects
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"maps"
+	"path"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.ufukty.com/gohandlers/internal/sorted"
+	"golang.org/x/tools/go/packages"
+)
+
+const loadMode = packages.NeedName |
+	packages.NeedFiles |
+	packages.NeedSyntax |
+	packages.NeedTypes |
+	packages.NeedTypesInfo
+
+// Load is the type-checked alternative of [Dir]. It loads the package in
+// dir with go/packages and builds the binding type infos from the resolved
+// types instead of the syntax. The package doesn't need to compile. Type
+// errors are expected as the helpers file might be missing or outdated.
+func Load(dir string) (*Package, error) {
+	return Conventions{}.Load(dir)
+}
+
+// Load is the type-checked alternative of [Conventions.Dir]
+func (c Conventions) Load(dir string) (*Package, error) {
+	ps, err := packages.Load(&packages.Config{Mode: loadMode, Dir: dir}, ".")
+	if err != nil {
+		return nil, fmt.Errorf("loading package: %w", err)
+	}
+	if len(ps) > 1 {
+		return nil, fmt.Errorf("found more than one packages")
+	} else if len(ps) == 0 {
+		return nil, fmt.Errorf("no packages found")
+	}
+	p := ps[0]
+
+	errs := []error{}
+	diagnostics := []Diagnostic{}
+	for _, err := range p.Errors {
+		if err.Kind == packages.TypeError {
+			diagnostics = append(diagnostics, Diagnostic{
+				Pos:      position(err.Pos),
+				Severity: Notice,
+				Code:     CodeTypeError,
+				Message:  err.Msg,
+			})
+			continue
+		}
+		// the output of the compiler for the package repeats the type errors
+		if err.Kind == packages.ListError && strings.HasPrefix(err.Msg, "# "+p.PkgPath+"\n") {
+			continue
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("loading package: %w", errors.Join(errs...))
+	}
+	if len(p.Syntax) == 0 {
+		return nil, fmt.Errorf("no Go files found")
+	}
+
+	files := map[string]*ast.File{}
+	for _, f := range p.Syntax {
+		files[p.Fset.Position(f.Pos()).Filename] = f
+	}
+
+	infoss, ds, err := inspect(source{fset: p.Fset, files: files, pkg: p.Types, info: p.TypesInfo, conv: c})
+	if err != nil {
+		return nil, err
+	}
+	diagnostics = append(diagnostics, ds...)
+	sortDiagnostics(diagnostics)
+	return &Package{
+		Name:        p.Name,
+		Handlers:    infoss,
+		Diagnostics: diagnostics,
+	}, nil
+}
+
+func btiFromType(tn string, t types.Type, pkg *types.Package) (*BindingTypeInfo, error) {
+	bti := newBindingTypeInfo(tn)
+	bti.Type = t
+	bti.Fields = map[string]types.Type{}
+	if st, ok := types.Unalias(t).Underlying().(*types.Struct); ok {
+		if err := fieldsFromType(bti, st, "", pkg); err != nil {
+			return nil, err
+		}
+	}
+	return bti.conclude()
+}
+
+// expr returns the expression pkg refers to the type with, and the imports
+// the expression needs
+func expr(t types.Type, pkg *types.Package) (ast.Expr, []*ast.ImportSpec, error) {
+	iss := []*ast.ImportSpec{}
+	s := types.TypeString(t, func(p *types.Package) string {
+		if p == pkg {
+			return ""
+		}
+		is := &ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(p.Path())}}
+		if p.Name() != path.Base(p.Path()) {
+			is.Name = ast.NewIdent(p.Name())
+		}
+		iss = append(iss, is)
+		return p.Name()
+	})
+	e, err := parser.ParseExpr(s)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing %s: %w", s, err)
+	}
+	return e, iss, nil
+}
+
+// fieldsFromType adds the tagged fields of struct to bti, by promoting
+// the fields of untagged embedded structs
+func fieldsFromType(bti *BindingTypeInfo, st *types.Struct, prefix string, pkg *types.Package) error {
+	for i := range st.NumFields() {
+		f := st.Field(i)
+		tag := reflect.StructTag(st.Tag(i))
+		if f.Embedded() && !tagged(tag) {
+			if est, ok := f.Type().Underlying().(*types.Struct); ok {
+				if err := fieldsFromType(bti, est, prefix+f.Name()+".", pkg); err != nil {
+					return err
+				}
+			} else if p, ok := f.Type().Underlying().(*types.Pointer); ok {
+				if _, ok := p.Elem().Underlying().(*types.Struct); ok {
+					return fmt.Errorf("%s: promoting fields of embedded pointer %s is not supported", bti.Typename, f.Name())
+				}
+			}
+			continue
+		}
+		if tagged(tag) {
+			if err := bti.field(tag, prefix+f.Name()); err != nil {
+				return err
+			}
+			bti.Fields[prefix+f.Name()] = f.Type()
+			if _, ok := tag.Lookup("json"); ok {
+				e, iss, err := expr(f.Type(), pkg)
+				if err != nil {
+					return fmt.Errorf("%s: type of %s: %w", bti.Typename, prefix+f.Name(), err)
+				}
+				bti.json(tag, prefix+f.Name(), e, iss)
+			}
+		}
+	}
+	return nil
+}
+
+// defined returns the resolved function object of the declaration
+func (src source) defined(fd *ast.FuncDecl) (*types.Func, bool) {
+	if src.info == nil {
+		return nil, false
+	}
+	fn, ok := src.info.Defs[fd.Name].(*types.Func)
+	return fn, ok
+}
+
+func isHttpType(t types.Type, name string) bool {
+	n, ok := types.Unalias(t).(*types.Named)
+	return ok && n.Obj().Pkg() != nil && n.Obj().Pkg().Path() == "net/http" && n.Obj().Name() == name
+}
+
+func isHandlerSignature(sig *types.Signature) bool {
+	if sig.Params().Len() != 2 || sig.Results().Len() != 0 {
+		return false
+	}
+	r, ok := types.Unalias(sig.Params().At(1).Type()).(*types.Pointer)
+	return ok && isHttpType(sig.Params().At(0).Type(), "ResponseWriter") && isHttpType(r.Elem(), "Request")
+}
+
+// factorySignature returns the name of the net/http type the function
+// returns when it returns either [http.HandlerFunc] or [http.Handler].
+// Functions taking either of them are middlewares rather than factories.
+func factorySignature(sig *types.Signature) string {
+	if sig.Results().Len() != 1 {
+		return ""
+	}
+	for i := range sig.Params().Len() {
+		t := sig.Params().At(i).Type()
+		if s, ok := t.(*types.Slice); ok && sig.Variadic() && i == sig.Params().Len()-1 {
+			t = s.Elem()
+		}
+		if isHttpType(t, "HandlerFunc") || isHttpType(t, "Handler") {
+			return ""
+		}
+	}
+	for _, t := range []string{"HandlerFunc", "Handler"} {
+		if isHttpType(sig.Results().At(0).Type(), t) {
+			return t
+		}
+	}
+	return ""
+}
+
+// uses checks if the handler body refers to the object
+func (src source) uses(h *ast.FuncDecl, obj types.Object) bool {
+	found := false
+	ast.Inspect(h.Body, func(n ast.Node) bool {
+		if i, ok := n.(*ast.Ident); ok && src.info.Uses[i] == obj {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// methods the generated helpers call on the field types of request
+// binding types, by parameter source
+var requested = map[string][]string{
+	"route":  {"FromRoute", "ToRoute", "Validate"},
+	"query":  {"FromQuery", "ToQuery", "Validate"},
+	"header": {"FromHeader", "ToHeader", "Validate"},
+	"cookie": {"FromCookie", "ToCookie", "Validate"},
+	"form":   {"FromForm", "ToForm", "Validate"},
+	"file":   {"FromFile", "ToFile", "Validate"},
+	"json":   {"Validate"},
+
+	// query fields with one of [ArrayEncodings]
+	"query-values": {"FromQueryValues", "ToQueryValues", "Validate"},
+	// query fields with the deep option
+	"query-object": {"FromQueryObject", "ToQueryObject", "Validate"},
+}
+
+// methods the generated helpers call on the field types of response
+// binding types, by parameter source
+var responded = map[string][]string{
+	"header": {"FromHeader", "ToHeader"},
+	"cookie": {"FromCookie", "ToCookie"},
+}
+
+// methods the generated helpers declare on binding types, by directive
+var bindingMethods = map[string][]string{
+	"gh:request":  {"Build", "Parse", "Validate"},
+	"gh:response": {"Write", "Parse"},
+}
+
+func missingMethods(t types.Type, names []string) []string {
+	// fields are addressable in generated code, so use the method set of pointer
+	ms := types.NewMethodSet(types.NewPointer(t))
+	missing := []string{}
+	for _, n := range names {
+		if ms.Lookup(nil, n) == nil {
+			missing = append(missing, n)
+		}
+	}
+	return missing
+}
+
+// checkFieldMethods lists the binding type fields whose types miss any of
+// the methods the generated code will call on them
+func checkFieldMethods(h *ast.FuncDecl, bti *BindingTypeInfo, required map[string][]string, pos token.Position) []Diagnostic {
+	if bti == nil || bti.Fields == nil {
+		return nil
+	}
+	sources := map[string]map[string]string{
+		"route":  bti.Params.Route,
+		"query":  bti.Params.Query,
+		"header": bti.Params.Header,
+		"cookie": bti.Params.Cookie,
+		"form":   bti.Params.Form,
+		"file":   bti.Params.File,
+		"json":   bti.Params.Json,
+	}
+	complaints := []Diagnostic{}
+	for _, src := range slices.Sorted(maps.Keys(sources)) {
+		if len(required[src]) == 0 {
+			continue
+		}
+		for param, fn := range sorted.ByValues(sources[src]) {
+			methods := required[src]
+			if src == "query" && bti.Arrays[param] != "" && len(required["query-values"]) > 0 {
+				methods = required["query-values"]
+			}
+			if src == "query" && bti.Objects[param] && len(required["query-object"]) > 0 {
+				methods = required["query-object"]
+			}
+			if missing := missingMethods(bti.Fields[fn], methods); len(missing) > 0 {
+				complaints = append(complaints, diagnose(pos, h, Error, CodeFieldMethods, "type of the %s field %s.%s is missing methods: %s",
+					src, bti.Typename, fn, strings.Join(missing, ", ")))
+			}
+		}
+	}
+	return complaints
+}
+
+// checkBodyField checks the raw body field is an interface that
+// [http.Request.Body] can be assigned to, such as io.ReadCloser or io.Reader
+func checkBodyField(h *ast.FuncDecl, bq *BindingTypeInfo, pos token.Position) []Diagnostic {
+	if bq == nil || bq.Fields == nil || bq.Body == "" {
+		return nil
+	}
+	t := bq.Fields[bq.Body]
+	if it, ok := t.Underlying().(*types.Interface); ok {
+		read, other := false, false
+		for m := range it.Methods() {
+			read = read || m.Name() == "Read"
+			other = other || (m.Name() != "Read" && m.Name() != "Close")
+		}
+		if read && !other {
+			return nil
+		}
+	}
+	return []Diagnostic{diagnose(pos, h, Error, CodeFieldType, "type of the body field %s.%s needs to be io.ReadCloser or io.Reader, not %s",
+		bq.Typename, bq.Body, t)}
+}
+
+// checkStatusField checks the status field is an int, as the generated
+// code assigns it from [http.Response.StatusCode]
+func checkStatusField(h *ast.FuncDecl, bs *BindingTypeInfo, pos token.Position) []Diagnostic {
+	if bs == nil || bs.Fields == nil || bs.Status == "" {
+		return nil
+	}
+	if !types.Identical(bs.Fields[bs.Status], types.Typ[types.Int]) {
+		return []Diagnostic{diagnose(pos, h, Error, CodeFieldType, "type of the status field %s.%s needs to be int, not %s",
+			bs.Typename, bs.Status, bs.Fields[bs.Status])}
+	}
+	return nil
+}
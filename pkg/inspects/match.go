@@ -0,0 +1,55 @@
+package inspects
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// PackageDir is a package matched by a pattern
+type PackageDir struct {
+	Dir  string // the directory contains the package files
+	Path string // import path; empty for directories given without pattern
+}
+
+// IsPattern reports if the argument is a package pattern (eg. ./services/...)
+// rather than a single directory
+func IsPattern(s string) bool {
+	return strings.Contains(s, "...")
+}
+
+// Match lists the packages matching the pattern with go list. Directories
+// are returned as is to not require the directory to be inside a module.
+func Match(pattern string) ([]PackageDir, error) {
+	if !IsPattern(pattern) {
+		return []PackageDir{{Dir: pattern}}, nil
+	}
+	ps, err := packages.Load(&packages.Config{Mode: packages.NeedName | packages.NeedFiles}, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("listing packages: %w", err)
+	}
+	errs := []error{}
+	dirs := []PackageDir{}
+	for _, p := range ps {
+		for _, err := range p.Errors {
+			errs = append(errs, err)
+		}
+		if p.Dir != "" {
+			dirs = append(dirs, PackageDir{Dir: p.Dir, Path: p.PkgPath})
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("listing packages: %w", errors.Join(errs...))
+	}
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("no packages matched %q", pattern)
+	}
+	slices.SortFunc(dirs, func(a, b PackageDir) int {
+		return cmp.Compare(a.Path, b.Path)
+	})
+	return dirs, nil
+}
@@ -0,0 +1,159 @@
+package inspects
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"unicode"
+)
+
+// Segment is a part of the path between slashes
+type Segment struct {
+	S     string // literal, wildcard name or "/" for "{$}"
+	Wild  bool
+	Multi bool // "{x...}", or a trailing slash with empty S
+}
+
+// Pattern is the host and path part of a [net/http.ServeMux] pattern
+type Pattern struct {
+	Host     string
+	Path     string // as written
+	Segments []Segment
+}
+
+// Anchored reports whether the path ends with "{$}"
+func (p *Pattern) Anchored() bool {
+	return len(p.Segments) > 0 && p.Segments[len(p.Segments)-1] == Segment{S: "/"}
+}
+
+// Subtree reports whether the path ends with a slash or a "{x...}"
+func (p *Pattern) Subtree() bool {
+	return len(p.Segments) > 0 && p.Segments[len(p.Segments)-1].Multi
+}
+
+// Wildcards returns the names of wildcards in order
+func (p *Pattern) Wildcards() []string {
+	ws := []string{}
+	for _, s := range p.Segments {
+		if s.Wild && s.S != "" {
+			ws = append(ws, s.S)
+		}
+	}
+	return ws
+}
+
+// Wildcard returns the segment of the named wildcard
+func (p *Pattern) Wildcard(name string) (Segment, bool) {
+	for _, s := range p.Segments {
+		if s.Wild && s.S == name {
+			return s, true
+		}
+	}
+	return Segment{}, false
+}
+
+// Placeholder returns the wildcard as written in the path, eg. "{x...}"
+func (s Segment) Placeholder() string {
+	if s.Multi {
+		return fmt.Sprintf("{%s...}", s.S)
+	}
+	return fmt.Sprintf("{%s}", s.S)
+}
+
+// Template is the path without the host and the "{$}" anchor, for
+// building request URLs by replacing the wildcards.
+func (p *Pattern) Template() string {
+	return strings.TrimSuffix(p.Path, "{$}")
+}
+
+func isWildcardName(s string) bool {
+	for i, c := range s {
+		if !unicode.IsLetter(c) && c != '_' && (i == 0 || !unicode.IsDigit(c)) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// cleanPath is [path.Clean] that keeps the trailing slash
+func cleanPath(p string) string {
+	c := path.Clean(p)
+	if strings.HasSuffix(p, "/") && c != "/" {
+		c += "/"
+	}
+	return c
+}
+
+// ParsePattern parses the host and path part of a pattern with the
+// grammar of [net/http.ServeMux] which is "[HOST]/[PATH]".
+func ParsePattern(s string) (*Pattern, error) {
+	if s == "" {
+		return nil, errors.New("empty pattern")
+	}
+	i := strings.IndexByte(s, '/')
+	if i == -1 {
+		return nil, errors.New("host/path missing /")
+	}
+	p := &Pattern{Host: s[:i], Path: s[i:]}
+	if strings.Contains(p.Host, "{") {
+		return nil, errors.New("host contains '{' (missing initial '/'?)")
+	}
+	if cleanPath(p.Path) != p.Path {
+		return nil, fmt.Errorf("unclean path %q can never match", p.Path)
+	}
+	seen := map[string]bool{}
+	rest := p.Path
+	for len(rest) > 0 {
+		rest = rest[1:]
+		if rest == "" {
+			p.Segments = append(p.Segments, Segment{Wild: true, Multi: true})
+			break
+		}
+		seg := rest
+		if i := strings.IndexByte(rest, '/'); i >= 0 {
+			seg, rest = rest[:i], rest[i:]
+		} else {
+			rest = ""
+		}
+		if !strings.Contains(seg, "{") {
+			u, err := url.PathUnescape(seg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid escape in segment %q", seg)
+			}
+			p.Segments = append(p.Segments, Segment{S: u})
+			continue
+		}
+		if seg[0] != '{' {
+			return nil, fmt.Errorf("bad wildcard segment %q (must start with '{')", seg)
+		}
+		if seg[len(seg)-1] != '}' {
+			return nil, fmt.Errorf("bad wildcard segment %q (must end with '}')", seg)
+		}
+		name := seg[1 : len(seg)-1]
+		if name == "$" {
+			if rest != "" {
+				return nil, errors.New("{$} not at end")
+			}
+			p.Segments = append(p.Segments, Segment{S: "/"})
+			break
+		}
+		name, multi := strings.CutSuffix(name, "...")
+		if multi && rest != "" {
+			return nil, fmt.Errorf("{%s...} wildcard not at end", name)
+		}
+		if name == "" {
+			return nil, errors.New("empty wildcard")
+		}
+		if !isWildcardName(name) {
+			return nil, fmt.Errorf("bad wildcard name %q", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate wildcard name %q", name)
+		}
+		seen[name] = true
+		p.Segments = append(p.Segments, Segment{S: name, Wild: true, Multi: multi})
+	}
+	return p, nil
+}
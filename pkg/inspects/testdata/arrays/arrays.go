@@ -0,0 +1,30 @@
+package arrays
+
+import (
+	"net/http"
+
+	"go.ufukty.com/gohandlers/pkg/types/basics"
+)
+
+type Pets struct{}
+
+type ListRequest struct {
+	Tags   basics.Strings   `query:"tag,repeat"`
+	Colors basics.Strings   `query:"colors,comma"`
+	Sort   basics.String    `query:"sort"`
+	Filter basics.StringMap `query:"filter,deep"`
+}
+
+func (p *Pets) List(w http.ResponseWriter, r *http.Request) {
+	_ = &ListRequest{}
+}
+
+type SearchRequest struct {
+	Tags   basics.String  `query:"tag,repeat"`
+	Owner  basics.Strings `query:"owner"`
+	Filter basics.Strings `query:"filter,deep"`
+}
+
+func (p *Pets) Search(w http.ResponseWriter, r *http.Request) {
+	_ = &SearchRequest{}
+}
@@ -0,0 +1,10 @@
+package broken
+
+import "net/http"
+
+type Pets struct{}
+
+// GET /pets
+func (p *Pets) List(w http.ResponseWriter, r *http.Request) {
+	p.Missing()
+}
@@ -0,0 +1,31 @@
+package conflicts
+
+import "net/http"
+
+type Pets struct{}
+
+// GET /pets/{id}
+func (p *Pets) Get(w http.ResponseWriter, r *http.Request) {}
+
+// GET /pets/{id}
+func (p *Pets) Visit(w http.ResponseWriter, r *http.Request) {}
+
+// HEAD /pets/{id}
+func (p *Pets) Head(w http.ResponseWriter, r *http.Request) {}
+
+// DELETE /pets/{id}
+func (p *Pets) Delete(w http.ResponseWriter, r *http.Request) {}
+
+// GET /pets/new
+func (p *Pets) New(w http.ResponseWriter, r *http.Request) {}
+
+type Owners struct{}
+
+// GET /{owner}/pets
+func (o *Owners) Pets(w http.ResponseWriter, r *http.Request) {}
+
+// HEAD /owners/{id}
+func (o *Owners) Head(w http.ResponseWriter, r *http.Request) {}
+
+// GET api.example.com/pets/{id}
+func (o *Owners) Api(w http.ResponseWriter, r *http.Request) {}
@@ -0,0 +1,24 @@
+package cookies
+
+import (
+	"net/http"
+
+	"go.ufukty.com/gohandlers/pkg/types/basics"
+)
+
+type Sessions struct{}
+
+type RefreshRequest struct {
+	Session  basics.String  `cookie:"session"`
+	Csrf     basics.String  `cookie:"csrf_token"`
+	Remember basics.Boolean `query:"remember"`
+}
+
+type RefreshResponse struct {
+	Expiry basics.Int `json:"expiry"`
+}
+
+func (s *Sessions) Refresh(w http.ResponseWriter, r *http.Request) {
+	_ = &RefreshRequest{}
+	_ = &RefreshResponse{}
+}
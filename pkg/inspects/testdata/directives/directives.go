@@ -0,0 +1,45 @@
+package directives
+
+import (
+	"net/http"
+
+	u "go.ufukty.com/gohandlers/pkg/inspects/testdata/directives/users"
+	"go.ufukty.com/gohandlers/pkg/types/basics"
+)
+
+type Users struct{}
+
+type UserId struct {
+	Id basics.String `route:"id"`
+}
+
+type User struct {
+	Name basics.String `json:"name"`
+}
+
+// GET /users/{id}
+// gh:request UserId
+// gh:response User
+func (us *Users) Get(w http.ResponseWriter, r *http.Request) {}
+
+// DELETE /users/{id}
+// gh:request UserId
+func (us *Users) Delete(w http.ResponseWriter, r *http.Request) {}
+
+// POST /users
+// gh:request u.CreateInput
+// gh:response User
+func (us *Users) Create(w http.ResponseWriter, r *http.Request) {
+	_ = &u.CreateInput{}
+}
+
+// gh:request Missing
+func (us *Users) Visit(w http.ResponseWriter, r *http.Request) {}
+
+// gh:name Find
+// gh:status 202
+// gh:tag users search
+func (us *Users) Lookup(w http.ResponseWriter, r *http.Request) {}
+
+// PSOT /users/search
+func (us *Users) Search(w http.ResponseWriter, r *http.Request) {}
@@ -0,0 +1,7 @@
+package users
+
+import "go.ufukty.com/gohandlers/pkg/types/basics"
+
+type CreateInput struct {
+	Name basics.String `json:"name"`
+}
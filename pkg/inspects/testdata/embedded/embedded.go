@@ -0,0 +1,41 @@
+package embedded
+
+import (
+	"net/http"
+
+	"go.ufukty.com/gohandlers/pkg/inspects/testdata/embedded/shared"
+)
+
+type Paging struct {
+	Limit  int `query:"limit"`
+	Offset int `query:"offset"`
+}
+
+type Tenant struct {
+	Id string `route:"tid"`
+}
+
+type Scope struct {
+	Tenant
+}
+
+type Pets struct{}
+
+type ListRequest struct {
+	Paging
+	Scope
+	Kind, Breed string `json:"kind"`
+}
+
+func (p *Pets) List(w http.ResponseWriter, r *http.Request) {
+	_ = &ListRequest{}
+}
+
+// SearchRequest embeds a type of another package named same as a local one
+type SearchRequest struct {
+	shared.Paging
+}
+
+func (p *Pets) Search(w http.ResponseWriter, r *http.Request) {
+	_ = &SearchRequest{}
+}
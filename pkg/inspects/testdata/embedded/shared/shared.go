@@ -0,0 +1,6 @@
+package shared
+
+type Paging struct {
+	Page int `query:"page"`
+	Size int `query:"size"`
+}
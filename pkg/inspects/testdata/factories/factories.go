@@ -0,0 +1,70 @@
+package factories
+
+import (
+	"net/http"
+	"time"
+
+	"go.ufukty.com/gohandlers/pkg/types/basics"
+)
+
+type Api struct{}
+
+type UploadRequest struct {
+	Name basics.String `route:"name"`
+}
+
+// GET
+func (a *Api) Upload(limit int, timeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_ = &UploadRequest{}
+	}
+}
+
+func (a *Api) Status(w http.ResponseWriter, r *http.Request) {}
+
+func (a *Api) Health() http.Handler {
+	return http.NotFoundHandler()
+}
+
+type DownloadRequest struct {
+	Name basics.String `route:"name"`
+}
+
+type Download struct{}
+
+// GET
+func (d *Download) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	_ = &DownloadRequest{}
+}
+
+func Logging(next http.Handler) http.Handler {
+	return next
+}
+
+func (a *Api) Chain(hs ...http.HandlerFunc) http.HandlerFunc {
+	return hs[0]
+}
+
+// gh:ignore
+func (a *Api) Routes() http.Handler {
+	return http.NewServeMux()
+}
+
+// GET
+func (a *Api) Search(fields ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {}
+}
+
+type Router struct{}
+
+func (r *Router) Mux() http.Handler {
+	return http.NewServeMux()
+}
+
+type Recoverer struct {
+	next http.Handler
+}
+
+func (rc *Recoverer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc.next.ServeHTTP(w, r)
+}
@@ -0,0 +1,25 @@
+package generic
+
+import "net/http"
+
+type Store[T any] struct {
+	items []T
+}
+
+func (s *Store[T]) Get(w http.ResponseWriter, r *http.Request) {}
+
+func (s *Store[T]) List(w http.ResponseWriter, r *http.Request) {}
+
+type Pair[K comparable, V any] struct{}
+
+func (p Pair[K, V]) Get(w http.ResponseWriter, r *http.Request) {}
+
+type Pets struct{}
+
+func (p Pets) List(w http.ResponseWriter, r *http.Request) {}
+
+func (p *Pets) Get(w http.ResponseWriter, r *http.Request) {}
+
+type Tags struct{}
+
+func (t Tags) List(w http.ResponseWriter, r *http.Request) {}
@@ -0,0 +1,45 @@
+package headers
+
+import (
+	"net/http"
+
+	"go.ufukty.com/gohandlers/pkg/types/basics"
+)
+
+// Tags is a multi-valued header
+type Tags []string
+
+func (t *Tags) FromHeader(vs []string) error {
+	*t = vs
+	return nil
+}
+
+func (t Tags) ToHeader() ([]string, error) { return t, nil }
+func (t Tags) Validate() any               { return nil }
+
+type Version string
+
+type Pets struct{}
+
+type GetRequest struct {
+	Tenant basics.String `header:"x-tenant-id"`
+	Id     basics.String `route:"id"`
+	Tags   Tags          `header:"X-Tags"`
+}
+
+type GetResponse struct {
+	Name string `json:"name"`
+}
+
+func (p *Pets) Get(w http.ResponseWriter, r *http.Request) {
+	_ = &GetRequest{}
+	_ = &GetResponse{}
+}
+
+type DeleteRequest struct {
+	IfMatch Version `header:"If-Match"`
+}
+
+func (p *Pets) Delete(w http.ResponseWriter, r *http.Request) {
+	_ = &DeleteRequest{}
+}
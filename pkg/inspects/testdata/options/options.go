@@ -0,0 +1,23 @@
+package options
+
+import (
+	"net/http"
+
+	"go.ufukty.com/gohandlers/pkg/types/basics"
+)
+
+type Pets struct{}
+
+type SearchRequest struct {
+	Owner  basics.String  `query:"owner,required"`
+	Colors basics.Strings `query:"colors,comma,default=black,white"`
+	Tenant basics.String  `header:"x-tenant,required"`
+	Name   basics.String  `json:"name,omitempty"`
+	Tags   basics.Strings `json:",omitempty"`
+	Note   string         `json:"-"`
+	Dash   basics.String  `json:"-,"`
+}
+
+func (p *Pets) Search(w http.ResponseWriter, r *http.Request) {
+	_ = &SearchRequest{}
+}
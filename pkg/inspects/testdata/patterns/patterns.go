@@ -0,0 +1,39 @@
+package patterns
+
+import (
+	"net/http"
+
+	"go.ufukty.com/gohandlers/pkg/types/basics"
+)
+
+type Files struct{}
+
+type DownloadRequest struct {
+	Path basics.String `route:"path"`
+}
+
+// GET /files/{path...}
+func (f *Files) Download(w http.ResponseWriter, r *http.Request) {
+	_ = &DownloadRequest{}
+}
+
+// GET /{$}
+func (f *Files) Index(w http.ResponseWriter, r *http.Request) {}
+
+// GET static.example.com/assets/
+func (f *Files) Assets(w http.ResponseWriter, r *http.Request) {}
+
+type BrowseRequest struct {
+	Dir basics.String `route:"dir"`
+}
+
+// GET /dirs/
+func (f *Files) Browse(w http.ResponseWriter, r *http.Request) {
+	_ = &BrowseRequest{}
+}
+
+// gh:prefix /dirs/{dir}
+type Dirs struct{}
+
+// GET /{dir}
+func (d *Dirs) Get(w http.ResponseWriter, r *http.Request) {}
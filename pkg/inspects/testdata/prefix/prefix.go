@@ -0,0 +1,46 @@
+package prefix
+
+import (
+	"net/http"
+
+	"go.ufukty.com/gohandlers/pkg/types/basics"
+)
+
+// Pets groups the handlers of pet resource
+//
+// gh:prefix /pets
+// gh:tag pets
+type Pets struct{}
+
+type GetRequest struct {
+	Id basics.String `route:"id"`
+}
+
+// GET /{id}
+func (p *Pets) Get(w http.ResponseWriter, r *http.Request) {
+	_ = &GetRequest{}
+}
+
+func (p *Pets) List(w http.ResponseWriter, r *http.Request) {}
+
+// gh:tag admin pets
+func (p *Pets) Delete(w http.ResponseWriter, r *http.Request) {}
+
+type (
+	// gh:prefix /owners/{oid}
+	Owners struct{}
+
+	VisitRequest struct {
+		Owner basics.String `route:"oid"`
+		Id    basics.String `route:"id"`
+	}
+)
+
+func (o *Owners) Visit(w http.ResponseWriter, r *http.Request) {
+	_ = &VisitRequest{}
+}
+
+// gh:list
+type Broken struct{}
+
+func (b Broken) Get(w http.ResponseWriter, r *http.Request) {}
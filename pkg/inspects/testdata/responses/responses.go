@@ -0,0 +1,39 @@
+package responses
+
+import (
+	"net/http"
+	"time"
+
+	"go.ufukty.com/gohandlers/pkg/types/basics"
+)
+
+type Pets struct{}
+
+type CreateRequest struct {
+	Name basics.String `json:"name"`
+}
+
+type CreateResponse struct {
+	Status   int           `status:""`
+	Location basics.String `header:"location"`
+	Session  basics.String `cookie:"session"`
+	Id       string        `json:"id"`
+	Born     time.Time     `json:"born,omitempty"`
+}
+
+// gh:status 201
+func (p *Pets) Create(w http.ResponseWriter, r *http.Request) {
+	_ = &CreateRequest{}
+	_ = &CreateResponse{}
+}
+
+type Etag string
+
+type GetResponse struct {
+	Status uint16 `status:""`
+	Etag   Etag   `header:"ETag"`
+}
+
+func (p *Pets) Get(w http.ResponseWriter, r *http.Request) {
+	_ = &GetResponse{}
+}
@@ -0,0 +1,5 @@
+package signatures
+
+import nethttp "net/http"
+
+func (p *Pets) Aliased(w nethttp.ResponseWriter, r *nethttp.Request) {}
@@ -0,0 +1,5 @@
+package signatures
+
+import . "net/http"
+
+func (p *Pets) Dotted(w ResponseWriter, r *Request) {}
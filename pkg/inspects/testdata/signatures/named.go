@@ -0,0 +1,19 @@
+package signatures
+
+import "net/http"
+
+type Pets struct{}
+
+func (p *Pets) Renamed(rw http.ResponseWriter, req *http.Request) {}
+
+func (p *Pets) Unnamed(http.ResponseWriter, *http.Request) {}
+
+func (p *Pets) Blank(_ http.ResponseWriter, _ *http.Request) {}
+
+// not handlers
+
+func (p *Pets) Returns(w http.ResponseWriter, r *http.Request) error { return nil }
+
+func (p *Pets) Extra(w http.ResponseWriter, r *http.Request, s string) {}
+
+func (p *Pets) Value(w http.ResponseWriter, r http.Request) {}
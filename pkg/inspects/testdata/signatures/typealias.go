@@ -0,0 +1,11 @@
+package signatures
+
+import "net/http"
+
+type (
+	Writer = http.ResponseWriter
+	Req    = http.Request
+)
+
+// only recognized with type information
+func (p *Pets) TypeAliased(w Writer, r *Req) {}
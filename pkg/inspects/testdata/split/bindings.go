@@ -0,0 +1,13 @@
+package split
+
+type CreateRequest struct {
+	Name string `json:"name"`
+}
+
+type CreateResponse struct {
+	Id string `json:"id"`
+}
+
+type DeleteRequest struct {
+	Id string `route:"id"`
+}
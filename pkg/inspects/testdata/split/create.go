@@ -0,0 +1,10 @@
+package split
+
+import "net/http"
+
+type Pets struct{}
+
+func (p *Pets) Create(w http.ResponseWriter, r *http.Request) {
+	_ = &CreateRequest{}
+	_ = &CreateResponse{}
+}
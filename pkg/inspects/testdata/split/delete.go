@@ -0,0 +1,5 @@
+package split
+
+import "net/http"
+
+func (p *Pets) Delete(w http.ResponseWriter, r *http.Request) {}
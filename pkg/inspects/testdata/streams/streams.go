@@ -0,0 +1,41 @@
+package streams
+
+import (
+	"io"
+	"net/http"
+
+	"go.ufukty.com/gohandlers/pkg/types/basics"
+)
+
+type Feeds struct{}
+
+type ImportRequest struct {
+	Tenant basics.String `header:"X-Tenant-Id"`
+	Items  io.ReadCloser `body:"application/x-ndjson"`
+}
+
+type ImportResponse struct {
+	Count basics.Int `json:"count"`
+}
+
+// gh:post
+func (f *Feeds) Import(w http.ResponseWriter, r *http.Request) {
+	_ = &ImportRequest{}
+	_ = &ImportResponse{}
+}
+
+type UploadRequest struct {
+	Blob io.Reader `body:""`
+}
+
+func (f *Feeds) Upload(w http.ResponseWriter, r *http.Request) {
+	_ = &UploadRequest{}
+}
+
+type ReplaceRequest struct {
+	Blob []byte `body:"image/png"`
+}
+
+func (f *Feeds) Replace(w http.ResponseWriter, r *http.Request) {
+	_ = &ReplaceRequest{}
+}
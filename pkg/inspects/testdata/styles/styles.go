@@ -0,0 +1,28 @@
+package styles
+
+import (
+	"net/http"
+
+	"go.ufukty.com/gohandlers/pkg/types/basics"
+)
+
+type Owners struct{}
+
+type GetPhotoRequest struct {
+	Owner basics.String `route:"owner"`
+	Pet   basics.String `route:"pet"`
+	Id    basics.String `route:"id"`
+}
+
+func (o *Owners) GetPhoto(w http.ResponseWriter, r *http.Request) {
+	_ = &GetPhotoRequest{}
+}
+
+func (o *Owners) Create(w http.ResponseWriter, r *http.Request) {}
+
+// gh:prefix /pets
+type Pets struct{}
+
+func (p *Pets) Delete(w http.ResponseWriter, r *http.Request) {}
+
+func Get(w http.ResponseWriter, r *http.Request) {}
@@ -0,0 +1,29 @@
+package typed
+
+import "net/http"
+
+type Id string
+
+func (i *Id) FromRoute(v string) error { return nil }
+func (i Id) ToRoute() (string, error)  { return string(i), nil }
+func (i Id) Validate() any             { return nil }
+
+type Name string
+
+type Pets struct{}
+
+type GetRequest struct {
+	Id   Id   `route:"id"`
+	Name Name `query:"name"`
+}
+
+type pet struct {
+	Name string `json:"name"`
+}
+
+type GetResponse = pet
+
+func (p *Pets) Get(w http.ResponseWriter, r *http.Request) {
+	_ = &GetRequest{}
+	_ = &GetResponse{}
+}
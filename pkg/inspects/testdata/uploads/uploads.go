@@ -0,0 +1,19 @@
+package uploads
+
+import (
+	"net/http"
+
+	"go.ufukty.com/gohandlers/pkg/types/basics"
+)
+
+type Pets struct{}
+
+type UploadPhotoRequest struct {
+	Id      basics.String `route:"id"`
+	Caption basics.String `form:"caption"`
+	Photo   basics.File   `file:"photo"`
+}
+
+func (p *Pets) UploadPhoto(w http.ResponseWriter, r *http.Request) {
+	_ = &UploadPhotoRequest{}
+}
@@ -2,7 +2,11 @@ package basics
 
 import (
 	"fmt"
+	"io"
+	"mime/multipart"
 	"strconv"
+
+	"go.ufukty.com/gohandlers/pkg/gohandlers"
 )
 
 type Boolean bool
@@ -36,6 +40,23 @@ func (b Boolean) ToQuery() (string, bool, error) {
 	return "f", true, nil
 }
 
+func (b *Boolean) FromHeader(vs []string) error {
+	return b.FromQuery(vs[0])
+}
+
+func (b Boolean) ToHeader() ([]string, error) {
+	v, _, err := b.ToQuery()
+	return []string{v}, err
+}
+
+func (b *Boolean) FromCookie(v string) error {
+	return b.FromQuery(v)
+}
+
+func (b Boolean) ToCookie() (string, bool, error) {
+	return b.ToQuery()
+}
+
 func (b Boolean) Validate() any { return nil }
 
 type FormBoolean bool
@@ -69,6 +90,18 @@ func (b FormBoolean) ToQuery() (string, bool, error) {
 	return "", false, nil
 }
 
+func (b *FormBoolean) FromForm(v string) error {
+	*b = v == "on"
+	return nil
+}
+
+func (b FormBoolean) ToForm() (string, error) {
+	if b {
+		return "on", nil
+	}
+	return "", nil
+}
+
 func (b FormBoolean) Validate() any { return nil }
 
 type String string
@@ -91,8 +124,63 @@ func (s String) ToQuery() (string, bool, error) {
 	return string(s), s != "", nil
 }
 
+func (s *String) FromHeader(vs []string) error {
+	*s = String(vs[0])
+	return nil
+}
+
+func (s String) ToHeader() ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	return []string{string(s)}, nil
+}
+
+func (s *String) FromCookie(v string) error {
+	return s.FromQuery(v)
+}
+
+func (s String) ToCookie() (string, bool, error) {
+	return s.ToQuery()
+}
+
+func (s *String) FromForm(v string) error {
+	*s = String(v)
+	return nil
+}
+
+func (s String) ToForm() (string, error) {
+	return string(s), nil
+}
+
 func (s String) Validate() any { return nil }
 
+type Strings []string
+
+func (s *Strings) FromQueryValues(vs []string) error {
+	*s = Strings(vs)
+	return nil
+}
+
+func (s Strings) ToQueryValues() ([]string, error) {
+	return []string(s), nil
+}
+
+func (s Strings) Validate() any { return nil }
+
+type StringMap map[string]string
+
+func (s *StringMap) FromQueryObject(m map[string]string) error {
+	*s = StringMap(m)
+	return nil
+}
+
+func (s StringMap) ToQueryObject() (map[string]string, error) {
+	return map[string]string(s), nil
+}
+
+func (s StringMap) Validate() any { return nil }
+
 type Int int
 
 func (i *Int) FromRoute(v string) error {
@@ -121,4 +209,55 @@ func (i Int) ToQuery() (string, bool, error) {
 	return strconv.Itoa(int(i)), i != 0, nil
 }
 
+func (i *Int) FromHeader(vs []string) error {
+	return i.FromQuery(vs[0])
+}
+
+func (i Int) ToHeader() ([]string, error) {
+	if i == 0 {
+		return nil, nil
+	}
+	return []string{strconv.Itoa(int(i))}, nil
+}
+
+func (i *Int) FromCookie(v string) error {
+	return i.FromQuery(v)
+}
+
+func (i Int) ToCookie() (string, bool, error) {
+	return i.ToQuery()
+}
+
+func (i *Int) FromForm(v string) error {
+	return i.FromQuery(v)
+}
+
+func (i Int) ToForm() (string, error) {
+	return strconv.Itoa(int(i)), nil
+}
+
 func (i Int) Validate() any { return nil }
+
+// File is a single file of multipart/form-data bodies. Request parsers
+// set the Header of the received file, and request builders send the
+// Content with the Filename when there is one.
+type File struct {
+	Header   *multipart.FileHeader
+	Filename string
+	Content  io.Reader
+}
+
+func (f *File) FromFile(fhs []*multipart.FileHeader) error {
+	f.Header = fhs[0]
+	f.Filename = fhs[0].Filename
+	return nil
+}
+
+func (f File) ToFile() ([]gohandlers.File, error) {
+	if f.Content == nil {
+		return nil, nil
+	}
+	return []gohandlers.File{{Name: f.Filename, Content: f.Content}}, nil
+}
+
+func (f File) Validate() any { return nil }